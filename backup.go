@@ -0,0 +1,199 @@
+package hilink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrEmptyBackup is the empty backup error.
+	ErrEmptyBackup = errors.New("empty backup")
+
+	// ErrBackupChecksum is the backup checksum mismatch error.
+	ErrBackupChecksum = errors.New("backup checksum mismatch")
+
+	// ErrRestoreRejected is the error returned when the device did not
+	// restart after a configuration backup was uploaded.
+	ErrRestoreRejected = errors.New("restore rejected by device")
+)
+
+// MaxBackupSize is the maximum size of a configuration backup accepted by
+// DeviceRestore.
+const MaxBackupSize = 4 * 1024 * 1024
+
+// BackupChecksum returns the hex encoded SHA-256 checksum of backup data.
+func BackupChecksum(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// VerifyBackup verifies backup data against a checksum returned by
+// BackupChecksum.
+func VerifyBackup(data []byte, sum string) error {
+	if len(data) == 0 {
+		return ErrEmptyBackup
+	}
+	if !strings.EqualFold(BackupChecksum(data), strings.TrimSpace(sum)) {
+		return ErrBackupChecksum
+	}
+	return nil
+}
+
+// doReqRaw retrieves the file with the provided path. The WebUI answers with
+// an XML error document, still with a 200 status code, when the file cannot
+// be retrieved (eg, without a session or login), returned as the device
+// error.
+func (c *Client) doReqRaw(path string) (buf []byte, err error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// notify hooks
+	var status int
+	done := c.observe("GET", path, nil)
+	defer func() {
+		done(status, buf, err)
+	}()
+
+	r, err := c.client.Get(c.rawurl + path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	// check status code
+	status = r.StatusCode
+	if r.StatusCode != http.StatusOK {
+		return nil, ErrBadStatusCode
+	}
+
+	buf, err = ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(buf), []byte("<?xml")) {
+		if _, err := decodeXML(buf, false); err != nil {
+			if _, ok := err.(*Error); ok {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// doUpload uploads a file using a multipart form, as done by the WebUI file
+// upload forms.
+func (c *Client) doUpload(path string, fields []string, field, filename string, data []byte) (err error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// build form
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if err := mw.WriteField(fields[i], fields[i+1]); err != nil {
+			return err
+		}
+	}
+	fw, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		return err
+	}
+	if _, err = fw.Write(data); err != nil {
+		return err
+	}
+	if err = mw.Close(); err != nil {
+		return err
+	}
+
+	// build req
+	req, err := http.NewRequest("POST", c.rawurl+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header[TokenHeader] = []string{c.token}
+
+	// notify hooks
+	var status int
+	var res interface{}
+	done := c.observe(req.Method, path, filename)
+	defer func() {
+		done(status, res, err)
+	}()
+
+	// do request
+	r, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	// check status code
+	status = r.StatusCode
+	if r.StatusCode != http.StatusOK {
+		return ErrBadStatusCode
+	}
+
+	// retrieve and save csrf token header
+	if tok := r.Header.Get(TokenHeader); tok != "" {
+		c.token = tok
+	}
+
+	// the response is an HTML page, unless the WebUI reports an error; the
+	// page is the same for accepted and rejected files
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(buf), []byte("<?xml")) {
+		res, err = decodeXML(buf, false)
+	}
+	return err
+}
+
+// DeviceRestore restores a device configuration backup retrieved with
+// DeviceBackup, by uploading it through the WebUI configuration restore form.
+// The backup data is verified against sum, as returned by BackupChecksum,
+// before being uploaded.
+//
+// The device reboots to apply the configuration. As the WebUI does not report
+// rejected uploads, DeviceRestore waits for the device to go down, returning
+// ErrRestoreRejected when it does not; use WaitForDevice before using the
+// client again.
+func (c *Client) DeviceRestore(ctx context.Context, r io.Reader, sum string) (bool, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxBackupSize+1))
+	switch {
+	case err != nil:
+		return false, err
+	case len(data) > MaxBackupSize:
+		return false, ErrInvalidValue
+	}
+	if err = VerifyBackup(data, sum); err != nil {
+		return false, err
+	}
+
+	err = c.doUpload("api/filemanager/upload", []string{
+		"cur_path", "RESTORE:nvram.bak",
+	}, "uploadfile", "nvram.bak", data)
+	if err != nil {
+		return false, err
+	}
+
+	down, err := c.waitDown(ctx)
+	switch {
+	case err != nil:
+		return false, err
+	case !down:
+		return false, ErrRestoreRejected
+	}
+
+	return true, nil
+}
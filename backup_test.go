@@ -0,0 +1,50 @@
+package hilink
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeviceBackup(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"backup", "\x00\x01backup data", ""},
+		{"error", `<?xml version="1.0" encoding="UTF-8"?><error><code>100003</code><message></message></error>`, "100003"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case "/api/device/control":
+					fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><response>OK</response>`)
+				case "/nvram.bak":
+					fmt.Fprint(w, test.body)
+				default:
+					http.NotFound(w, req)
+				}
+			}))
+			defer s.Close()
+
+			client, err := NewClient(URL(s.URL), NoSessionStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf, err := client.DeviceBackup()
+			switch {
+			case test.code != "" && !IsErrorCode(err, test.code):
+				t.Errorf("expected error code %s, got: %v", test.code, err)
+			case test.code != "" && buf != nil:
+				t.Errorf("expected no data, got: %q", buf)
+			case test.code == "" && err != nil:
+				t.Errorf("expected no error, got: %v", err)
+			case test.code == "" && string(buf) != test.body:
+				t.Errorf("expected %q, got: %q", test.body, buf)
+			}
+		})
+	}
+}
@@ -13,13 +13,32 @@ import (
 var (
 	flagEndpoint = flag.String("endpoint", "http://192.168.8.1/", "api endpoint")
 	flagDebug    = flag.Bool("v", false, "enable verbose")
+	flagSnapshot = flag.Bool("snapshot", false, "dump a snapshot of the device state")
+	flagDiff     = flag.Bool("diff", false, "compare snapshot files (<old> [<new>], using the device when <new> is omitted)")
+	flagAll      = flag.Bool("all", false, "include volatile sections (traffic, signal, ...) in diff")
+	flagNoRedact = flag.Bool("unredacted", false, "do not redact sensitive information (debugging only)")
 )
 
 func main() {
-	var err error
-
 	flag.Parse()
 
+	var err error
+	switch {
+	case *flagDiff:
+		err = diff(flag.Args())
+	case *flagSnapshot:
+		err = snapshot()
+	default:
+		err = info()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newClient creates the client.
+func newClient() (*hilink.Client, error) {
 	// options
 	opts := []hilink.Option{
 		hilink.URL(*flagEndpoint),
@@ -27,27 +46,121 @@ func main() {
 	if *flagDebug {
 		opts = append(opts, hilink.Log(log.Printf, log.Printf))
 	}
+	if *flagNoRedact {
+		opts = append(opts, hilink.Redact(nil))
+	}
 
-	// create client
-	client, err := hilink.NewClient(opts...)
+	return hilink.NewClient(opts...)
+}
+
+// redactor returns the redactor for the output, or nil when redaction is
+// disabled.
+func redactor() *hilink.Redactor {
+	if *flagNoRedact {
+		return nil
+	}
+	return hilink.DefaultRedactor()
+}
+
+// info prints the device info.
+func info() error {
+	client, err := newClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
 	// get device info
 	d, err := client.DeviceInfo()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+	if r := redactor(); r != nil {
+		d = r.XMLData(d)
+	}
+
+	return printJSON(d)
+}
+
+// snapshot prints a snapshot of the device state.
+func snapshot() error {
+	client, err := newClient()
+	if err != nil {
+		return err
 	}
 
-	// change to json
-	v, err := json.MarshalIndent(d, "", "  ")
+	s, err := client.Snapshot()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+	if r := redactor(); r != nil {
+		s = r.Snapshot(s)
+	}
+
+	return printJSON(s)
+}
+
+// diff prints the changes between two snapshots.
+func diff(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("-diff expects 1 or 2 snapshot files")
+	}
+
+	a, err := loadSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+
+	var b *hilink.Snapshot
+	if len(args) == 2 {
+		b, err = loadSnapshot(args[1])
+	} else {
+		var client *hilink.Client
+		if client, err = newClient(); err == nil {
+			b, err = client.Snapshot()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// files may have been written with or without redaction
+	if r := redactor(); r != nil {
+		a, b = r.Snapshot(a), r.Snapshot(b)
+	}
+
+	var ignore []string
+	if !*flagAll {
+		ignore = hilink.DefaultSnapshotIgnore
+	}
+	for _, c := range hilink.DiffSnapshots(a, b, ignore...) {
+		fmt.Fprintln(os.Stdout, c)
+	}
+
+	return nil
+}
+
+// loadSnapshot reads a snapshot file.
+func loadSnapshot(path string) (*hilink.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := hilink.LoadSnapshot(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return s, nil
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(v interface{}) error {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintf(os.Stdout, "%s\n", string(v))
+	fmt.Fprintf(os.Stdout, "%s\n", string(buf))
+	return nil
 }
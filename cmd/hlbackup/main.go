@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jpunie/hilink"
+)
+
+var (
+	flagEndpoint = flag.String("endpoint", "http://192.168.8.1/", "api endpoint")
+	flagDebug    = flag.Bool("v", false, "enable verbose")
+	flagUser     = flag.String("user", "", "username")
+	flagPass     = flag.String("pass", "", "password")
+	flagDir      = flag.String("dir", ".", "backup directory")
+	flagKeep     = flag.Int("keep", 0, "number of backups to keep per device (0 keeps all)")
+	flagRestore  = flag.String("restore", "", "backup file to restore")
+	flagTimeout  = flag.Duration("timeout", 3*time.Minute, "time to wait for the device after restore")
+)
+
+func main() {
+	flag.Parse()
+
+	// options
+	opts := []hilink.Option{
+		hilink.URL(*flagEndpoint),
+	}
+	if *flagUser != "" {
+		opts = append(opts, hilink.Auth(*flagUser, *flagPass))
+	}
+	if *flagDebug {
+		opts = append(opts, hilink.Log(log.Printf, log.Printf))
+	}
+
+	// create client
+	client, err := hilink.NewClient(opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *flagRestore != "" {
+		err = restore(client, *flagRestore)
+	} else {
+		err = backup(client)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// backup retrieves a configuration backup, and writes it along with its
+// checksum file to the backup directory.
+func backup(client *hilink.Client) error {
+	d, err := client.DeviceInfo()
+	if err != nil {
+		return err
+	}
+	// name backups after the device, using the IMEI when the serial
+	// number is not reported
+	device, _ := d["DeviceName"].(string)
+	if device == "" {
+		device = "hilink"
+	}
+	serial, _ := d["SerialNumber"].(string)
+	if serial == "" {
+		serial, _ = d["Imei"].(string)
+	}
+	if serial == "" {
+		return fmt.Errorf("unable to identify device: no serial number or IMEI")
+	}
+	prefix := sanitize(device + "-" + serial)
+
+	data, err := client.DeviceBackup()
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return hilink.ErrEmptyBackup
+	}
+
+	// write backup and checksum, using the sha256sum format
+	name := fmt.Sprintf("%s-%s.bak", prefix, time.Now().Format("20060102-150405"))
+	path := filepath.Join(*flagDir, name)
+	if err = ioutil.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	sum := fmt.Sprintf("%s  %s\n", hilink.BackupChecksum(data), name)
+	if err = ioutil.WriteFile(path+".sha256", []byte(sum), 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", path)
+
+	if *flagKeep > 0 {
+		return prune(prefix, *flagKeep)
+	}
+	return nil
+}
+
+// restore verifies a backup file against its checksum file, and restores it.
+func restore(client *hilink.Client, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sum, err := ioutil.ReadFile(path + ".sha256")
+	if err != nil {
+		return err
+	}
+	f := strings.Fields(string(sum))
+	if len(f) == 0 {
+		return hilink.ErrBackupChecksum
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flagTimeout)
+	defer cancel()
+
+	// the device goes down once the backup is accepted
+	ok, err := client.DeviceRestore(ctx, bytes.NewReader(data), f[0])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("unable to restore %s", path)
+	}
+
+	return client.WaitForDevice(ctx)
+}
+
+// prune removes the oldest backups of a device, keeping the keep most recent
+// ones.
+func prune(prefix string, keep int) error {
+	files, err := filepath.Glob(filepath.Join(*flagDir, prefix+"-*.bak"))
+	if err != nil {
+		return err
+	}
+	if len(files) <= keep {
+		return nil
+	}
+
+	// the timestamp suffix sorts chronologically
+	sort.Strings(files)
+	for _, f := range files[:len(files)-keep] {
+		if err = os.Remove(f); err != nil {
+			return err
+		}
+		if err = os.Remove(f + ".sha256"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// sanitize makes s safe for use in a file name.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jpunie/hilink"
+	"github.com/jpunie/hilink/config"
+)
+
+// doApply applies a configuration document to the device, printing the plan
+// first.
+func doApply(args []string) {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	flagDebug := fs.Bool("v", false, "enable verbose")
+	flagEndpoint := fs.String("endpoint", "http://192.168.8.1/", "api endpoint")
+	flagDryRun := fs.Bool("n", false, "print the plan without applying it")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s apply [<params>] <file>\n\nParameters for apply:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := config.LoadFile(fs.Arg(0))
+	if err != nil {
+		doExit("error: %v", err)
+	}
+
+	// hilink options
+	opts := []hilink.Option{
+		hilink.URL(*flagEndpoint),
+	}
+	if *flagDebug {
+		opts = append(opts, hilink.Log(log.Printf, log.Printf))
+	}
+
+	// create client
+	client, err := hilink.NewClient(opts...)
+	if err != nil {
+		doExit("error: %v", err)
+	}
+
+	plan, err := config.NewPlan(client, doc)
+	if err != nil {
+		doExit("error: %v", err)
+	}
+	os.Stdout.WriteString(plan.String())
+	if *flagDryRun || plan.Empty() {
+		return
+	}
+
+	if err = plan.Apply(); err != nil {
+		doExit("error: %v", err)
+	}
+	os.Stdout.WriteString("OK\n")
+}
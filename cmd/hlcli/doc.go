@@ -3,193 +3,357 @@ package main
 // Code generated by gen.go. DO NOT EDIT.
 
 var methodParamMap = map[string][]string{
-	"NewSessionAndTokenID": {},
-	"SetSessionAndTokenID": {"sessionID", "tokenID"},
-	"GlobalConfig":         {},
-	"NetworkTypes":         {},
-	"PCAssistantConfig":    {},
-	"DeviceConfig":         {},
-	"WebUIConfig":          {},
-	"SmsConfig":            {},
-	"WlanConfig":           {},
-	"DhcpConfig":           {},
-	"CradleStatusInfo":     {},
-	"CradleMACSet":         {"addr"},
-	"CradleMAC":            {},
-	"AutorunVersion":       {},
-	"DeviceBasicInfo":      {},
-	"PublicKey":            {},
-	"DeviceControl":        {"code"},
-	"DeviceReboot":         {},
-	"DeviceReset":          {},
-	"DeviceBackup":         {},
-	"DeviceShutdown":       {},
-	"DeviceFeatures":       {},
-	"DeviceInfo":           {},
-	"DeviceModeSet":        {"mode"},
-	"FastbootFeatures":     {},
-	"PowerFeatures":        {},
-	"TetheringFeatures":    {},
-	"SignalInfo":           {},
-	"ConnectionInfo":       {},
-	"ConnectionProfile":    {"roaming", "maxIdleTime"},
-	"GlobalFeatures":       {},
-	"Language":             {},
-	"LanguageSet":          {"lang"},
-	"NotificationInfo":     {},
-	"SimInfo":              {},
-	"StatusInfo":           {},
-	"TrafficInfo":          {},
-	"TrafficClear":         {},
-	"MonthInfo":            {},
-	"WlanMonthInfo":        {},
-	"NetworkInfo":          {},
-	"WifiFeatures":         {},
-	"ModeList":             {},
-	"ModeInfo":             {},
-	"ModeNetworkInfo":      {},
-	"ModeSet":              {"netMode", "netBand", "lteBand"},
-	"PinInfo":              {},
-	"PinEnter":             {"pin"},
-	"PinActivate":          {"pin"},
-	"PinDeactivate":        {"pin"},
-	"PinChange":            {"pin", "new"},
-	"PinEnterPuk":          {"puk", "new"},
-	"PinSaveInfo":          {},
-	"PinSimlockInfo":       {},
-	"Connect":              {},
-	"Disconnect":           {},
-	"ProfileInfo":          {},
-	"ProfileAdd":           {"name", "apn", "user", "password", "isDefault"},
-	"ProfileDelete":        {"index", "newDefault"},
-	"SmsFeatures":          {},
-	"SmsList":              {"boxType", "page", "count", "sortByName", "ascending", "unreadPreferred"},
-	"SmsCount":             {},
-	"SmsSend":              {"msg", "to"},
-	"SmsSendStatus":        {},
-	"SmsReadSet":           {"id"},
-	"SmsDelete":            {"id"},
-	"UssdStatus":           {},
-	"UssdCode":             {"code"},
-	"UssdContent":          {},
-	"UssdRelease":          {},
-	"DdnsList":             {},
-	"LogPath":              {},
-	"LogInfo":              {},
-	"PhonebookGroupList":   {"page", "count", "sortByName", "ascending"},
-	"PhonebookCount":       {},
-	"PhonebookImport":      {"group"},
-	"PhonebookDelete":      {"id"},
-	"PhonebookList":        {"group", "page", "count", "sim", "sortByName", "ascending", "keyword"},
-	"PhonebookCreate":      {"group", "name", "phone", "sim"},
-	"FirewallFeatures":     {},
-	"DmzConfig":            {},
-	"DmzConfigSet":         {"enabled", "dmzIPAddress"},
-	"SipAlg":               {},
-	"SipAlgSet":            {"port", "enabled"},
-	"NatType":              {},
-	"NatTypeSet":           {"ntype"},
-	"Upnp":                 {},
-	"UpnpSet":              {"enabled"},
-	"PrivacyPolicy":        {"agree"},
-	"AutoUpdate":           {"enabled"},
-	"BasicDeviceInfo":      {"restore"},
-	"OnlineUpdateInfo":     {},
-	"OnlineUpdateConfig":   {"autoUpdateEnabled", "serverForceEnabled"},
+	"DeviceRestore":            {"ctx", "r", "sum"},
+	"DdnsEntries":              {},
+	"DdnsCreate":               {"e"},
+	"DdnsUpdate":               {"index", "e"},
+	"DdnsDelete":               {"index"},
+	"DhcpSettings":             {},
+	"DhcpSettingsSet":          {"s"},
+	"StaticLeases":             {},
+	"StaticLeasesSet":          {"leases"},
+	"StaticLeaseAdd":           {"mac", "ip"},
+	"StaticLeaseRemove":        {"mac"},
+	"DialupSettings":           {},
+	"DialupSettingsSet":        {"update"},
+	"FirewallSwitches":         {},
+	"FirewallSwitchesSet":      {"s"},
+	"IPFilterRules":            {},
+	"IPFilterRulesSet":         {"rules"},
+	"URLFilterRules":           {},
+	"URLFilterRulesSet":        {"rules"},
+	"GuestWlan":                {},
+	"GuestWlanSet":             {"g"},
+	"GuestWlanEnable":          {"enabled"},
+	"WlanSchedule":             {},
+	"WlanScheduleSet":          {"s"},
+	"WlanScheduleEnable":       {"enabled"},
+	"DoJson":                   {"path", "v"},
+	"NewSessionAndTokenID":     {},
+	"SetSessionAndTokenID":     {"sessionID", "tokenID"},
+	"GlobalConfig":             {},
+	"NetworkTypes":             {},
+	"PCAssistantConfig":        {},
+	"DeviceConfig":             {},
+	"WebUIConfig":              {},
+	"SmsConfig":                {},
+	"WlanConfig":               {},
+	"DhcpConfig":               {},
+	"CradleStatusInfo":         {},
+	"CradleMACSet":             {"addr"},
+	"CradleMAC":                {},
+	"AutorunVersion":           {},
+	"DeviceBasicInfo":          {},
+	"PublicKey":                {},
+	"DeviceControl":            {"code"},
+	"DeviceReboot":             {},
+	"DeviceReset":              {},
+	"DeviceBackup":             {},
+	"DeviceShutdown":           {},
+	"DeviceFeatures":           {},
+	"DeviceInfo":               {},
+	"DeviceModeSet":            {"mode"},
+	"FastbootFeatures":         {},
+	"PowerFeatures":            {},
+	"TetheringFeatures":        {},
+	"SignalInfo":               {},
+	"ConnectionInfo":           {},
+	"ConnectionProfile":        {"roaming", "maxIdleTime"},
+	"GlobalFeatures":           {},
+	"Language":                 {},
+	"LanguageSet":              {"lang"},
+	"NotificationInfo":         {},
+	"SimInfo":                  {},
+	"StatusInfo":               {},
+	"TrafficInfo":              {},
+	"TrafficClear":             {},
+	"MonthInfo":                {},
+	"WlanMonthInfo":            {},
+	"NetworkInfo":              {},
+	"WifiFeatures":             {},
+	"ModeList":                 {},
+	"ModeInfo":                 {},
+	"ModeNetworkInfo":          {},
+	"ModeSet":                  {"netMode", "netBand", "lteBand"},
+	"NetworkRegisterInfo":      {},
+	"NetworkRegister":          {"mode", "plmn", "rat"},
+	"PinInfo":                  {},
+	"PinEnter":                 {"pin"},
+	"PinActivate":              {"pin"},
+	"PinDeactivate":            {"pin"},
+	"PinChange":                {"pin", "new"},
+	"PinEnterPuk":              {"puk", "new"},
+	"PinSaveInfo":              {},
+	"PinSimlockInfo":           {},
+	"MobileDataSwitch":         {},
+	"MobileDataSwitchState":    {"state"},
+	"MobileDataActivate":       {},
+	"MobileDataDeactivate":     {},
+	"Connect":                  {},
+	"Disconnect":               {},
+	"ProfileInfo":              {},
+	"ProfileAdd":               {"name", "apn", "user", "password", "isDefault"},
+	"ProfileDelete":            {"index", "newDefault"},
+	"SmsFeatures":              {},
+	"SmsList":                  {"boxType", "page", "count", "sortByName", "ascending", "unreadPreferred"},
+	"SmsCount":                 {},
+	"SmsSend":                  {"msg", "to"},
+	"SmsSendStatus":            {},
+	"SmsReadSet":               {"id"},
+	"SmsDelete":                {"id"},
+	"UssdStatus":               {},
+	"UssdCode":                 {"code"},
+	"UssdContent":              {},
+	"UssdRelease":              {},
+	"DdnsList":                 {},
+	"LogPath":                  {},
+	"LogInfo":                  {},
+	"PhonebookGroupList":       {"page", "count", "sortByName", "ascending"},
+	"PhonebookCount":           {},
+	"PhonebookImport":          {"group"},
+	"PhonebookDelete":          {"id"},
+	"PhonebookList":            {"group", "page", "count", "sim", "sortByName", "ascending", "keyword"},
+	"PhonebookCreate":          {"group", "name", "phone", "sim"},
+	"FirewallFeatures":         {},
+	"DmzConfig":                {},
+	"DmzConfigSet":             {"enabled", "dmzIPAddress"},
+	"SipAlg":                   {},
+	"SipAlgSet":                {"port", "enabled"},
+	"NatType":                  {},
+	"NatTypeSet":               {"ntype"},
+	"Upnp":                     {},
+	"UpnpSet":                  {"enabled"},
+	"PrivacyPolicy":            {"agree"},
+	"AutoUpdate":               {"enabled"},
+	"BasicDeviceInfo":          {"restore"},
+	"OnlineUpdateConfig":       {"autoUpdateEnabled", "serverForceEnabled"},
+	"OnlineUpdateInfo":         {},
+	"HostList":                 {},
+	"MacFilter":                {},
+	"MacFilterSet":             {"f"},
+	"MacFilterModeSet":         {"mode"},
+	"MacFilterAdd":             {"mac", "hostname"},
+	"MacFilterRemove":          {"mac"},
+	"PortForwards":             {},
+	"PortForwardsSet":          {"rules"},
+	"PortForwardAdd":           {"f"},
+	"PortForwardUpdate":        {"index", "f"},
+	"PortForwardDelete":        {"index"},
+	"ProfileList":              {},
+	"ProfileByName":            {"name"},
+	"ProfileByIndex":           {"index"},
+	"ProfileCreate":            {"p"},
+	"ProfileUpdate":            {"index", "p"},
+	"ProfileRemove":            {"index", "newDefault"},
+	"ProfileSetDefault":        {"index"},
+	"Provision":                {"db"},
+	"StaticRoutes":             {},
+	"StaticRoutesSet":          {"routes"},
+	"StaticRouteAdd":           {"destination", "netmask", "gateway", "iface"},
+	"StaticRouteRemove":        {"destination", "netmask"},
+	"WanDNS":                   {},
+	"LanDNSSet":                {"primary", "secondary"},
+	"Signal":                   {},
+	"Snapshot":                 {},
+	"SpecialApplications":      {},
+	"SpecialApplicationsSet":   {"apps"},
+	"SpecialApplicationAdd":    {"name", "triggerPort", "triggerProtocol", "openPorts", "openProtocol"},
+	"SpecialApplicationRemove": {"index"},
+	"Status":                   {},
+	"TrafficStats":             {},
+	"MonthStats":               {},
+	"DataPlan":                 {},
+	"DataPlanSet":              {"p"},
+	"WaitForDevice":            {"ctx"},
+	"WaitForRegistration":      {"ctx"},
+	"WaitForConnected":         {"ctx"},
+	"WaitForRestart":           {"ctx"},
+	"RebootAndWait":            {"ctx"},
+	"WlanSettings":             {},
+	"WlanSettingsSet":          {"index", "update"},
+	"WpsInfo":                  {},
+	"WpsState":                 {},
+	"WpsPushButton":            {},
+	"WpsPushButtonAndWait":     {"ctx"},
+	"WpsAPPin":                 {},
+	"WpsAPPinSet":              {"pin"},
+	"WpsAPPinGenerate":         {},
 }
 
 var methodCommentMap = map[string]string{
-	"NewSessionAndTokenID": "NewSessionAndTokenID starts a session with the server, and returns the session and token.",
-	"SetSessionAndTokenID": "SetSessionAndTokenID sets the sessionID and tokenID for the Client.",
-	"GlobalConfig":         "GlobalConfig retrieves global Hilink configuration.",
-	"NetworkTypes":         "NetworkTypes retrieves available network types.",
-	"PCAssistantConfig":    "PCAssistantConfig retrieves PC Assistant configuration.",
-	"DeviceConfig":         "DeviceConfig retrieves device configuration.",
-	"WebUIConfig":          "WebUIConfig retrieves WebUI configuration.",
-	"SmsConfig":            "SmsConfig retrieves device SMS configuration.",
-	"WlanConfig":           "WlanConfig retrieves basic WLAN settings.",
-	"DhcpConfig":           "DhcpConfig retrieves DHCP configuration.",
-	"CradleStatusInfo":     "CradleStatusInfo retrieves cradle status information.",
-	"CradleMACSet":         "CradleMACSet sets the MAC address for the cradle.",
-	"CradleMAC":            "CradleMAC retrieves cradle MAC address.",
-	"AutorunVersion":       "AutorunVersion retrieves device autorun version.",
-	"DeviceBasicInfo":      "DeviceBasicInfo retrieves basic device information.",
-	"PublicKey":            "PublicKey retrieves webserver public key.",
-	"DeviceControl":        "DeviceControl sends a control code to the device.",
-	"DeviceReboot":         "DeviceReboot restarts the device.",
-	"DeviceReset":          "DeviceReset resets the device configuration.",
-	"DeviceBackup":         "DeviceBackup backups device configuration and retrieves backed up configuration data as a base64 encoded string.",
-	"DeviceShutdown":       "DeviceShutdown shuts down the device.",
-	"DeviceFeatures":       "DeviceFeatures retrieves device feature information.",
-	"DeviceInfo":           "DeviceInfo retrieves general device information.",
-	"DeviceModeSet":        "DeviceModeSet sets the device mode (0-project, 1-debug).",
-	"FastbootFeatures":     "FastbootFeatures retrieves fastboot feature information.",
-	"PowerFeatures":        "PowerFeatures retrieves power feature information.",
-	"TetheringFeatures":    "TetheringFeatures retrieves USB tethering feature information.",
-	"SignalInfo":           "SignalInfo retrieves network signal information.",
-	"ConnectionInfo":       "ConnectionInfo retrieves connection (dialup) information.",
-	"ConnectionProfile":    "ConnectionProfile set connection (dialup) information for roaming and max idle time.",
-	"GlobalFeatures":       "GlobalFeatures retrieves global feature information.",
-	"Language":             "Language retrieves current language.",
-	"LanguageSet":          "LanguageSet sets the language.",
-	"NotificationInfo":     "NotificationInfo retrieves notification information.",
-	"SimInfo":              "SimInfo retrieves SIM card information.",
-	"StatusInfo":           "StatusInfo retrieves general device status information.",
-	"TrafficInfo":          "TrafficInfo retrieves traffic statistic information.",
-	"TrafficClear":         "TrafficClear clears the current traffic statistics.",
-	"MonthInfo":            "MonthInfo retrieves the month download statistic information.",
-	"WlanMonthInfo":        "WlanMonthInfo retrieves the WLAN month download statistic information.",
-	"NetworkInfo":          "NetworkInfo retrieves network provider information.",
-	"WifiFeatures":         "WifiFeatures retrieves wifi feature information.",
-	"ModeList":             "ModeList retrieves available network modes.",
-	"ModeInfo":             "ModeInfo retrieves network mode settings information.",
-	"ModeNetworkInfo":      "ModeNetworkInfo retrieves current network mode information.",
-	"ModeSet":              "ModeSet sets the network mode.",
-	"PinInfo":              "PinInfo retrieves SIM PIN status information.",
-	"PinEnter":             "PinEnter enters a SIM PIN.",
-	"PinActivate":          "PinActivate activates a SIM PIN.",
-	"PinDeactivate":        "PinDeactivate deactivates a SIM PIN.",
-	"PinChange":            "PinChange changes a SIM PIN.",
-	"PinEnterPuk":          "PinEnterPuk enters a SIM PIN puk.",
-	"PinSaveInfo":          "PinSaveInfo retrieves SIM PIN save information.",
-	"PinSimlockInfo":       "PinSimlockInfo retrieves SIM lock information.",
-	"Connect":              "Connect connects the Hilink device to the network provider.",
-	"Disconnect":           "Disconnect disconnects the Hilink device from the network provider.",
-	"ProfileInfo":          "ProfileInfo retrieves profile information (ie, APN).",
-	"ProfileAdd":           "Add connection profile and set new default profile",
-	"ProfileDelete":        "Delete connection profile an set new default profile",
-	"SmsFeatures":          "SmsFeatures retrieves SMS feature information.",
-	"SmsList":              "SmsList retrieves list of SMS in an inbox.",
-	"SmsCount":             "SmsCount retrieves count of SMS per inbox type.",
-	"SmsSend":              "SmsSend sends an SMS.",
-	"SmsSendStatus":        "SmsSendStatus retrieves SMS send status information.",
-	"SmsReadSet":           "SmsReadSet sets the read status of a SMS.",
-	"SmsDelete":            "SmsDelete deletes a specified SMS.",
-	"UssdStatus":           "UssdStatus retrieves current USSD session status information.",
-	"UssdCode":             "UssdCode sends a USSD code to the Hilink device.",
-	"UssdContent":          "UssdContent retrieves content buffer of the active USSD session.",
-	"UssdRelease":          "UssdRelease releases the active USSD session.",
-	"DdnsList":             "DdnsList retrieves list of DDNS providers.",
-	"LogPath":              "LogPath retrieves device log path (URL).",
-	"LogInfo":              "LogInfo retrieves current log setting information.",
-	"PhonebookGroupList":   "PhonebookGroupList retrieves list of the phonebook groups.",
-	"PhonebookCount":       "PhonebookCount retrieves count of phonebook entries per group.",
-	"PhonebookImport":      "PhonebookImport imports SIM contacts into specified phonebook group.",
-	"PhonebookDelete":      "PhonebookDelete deletes a specified phonebook entry.",
-	"PhonebookList":        "PhonebookList retrieves list of phonebook entries from a specified group.",
-	"PhonebookCreate":      "PhonebookCreate creates a new phonebook entry.",
-	"FirewallFeatures":     "FirewallFeatures retrieves firewall security feature information.",
-	"DmzConfig":            "DmzConfig retrieves DMZ status and IP address of DMZ host.",
-	"DmzConfigSet":         "DmzConfigSet enables or disables the DMZ and the DMZ IP address of the device.",
-	"SipAlg":               "SipAlg retrieves status and port of the SIP application-level gateway.",
-	"SipAlgSet":            "SipAlgSet enables/disables SIP application-level gateway and sets SIP port.",
-	"NatType":              "NatType retrieves NAT type.",
-	"NatTypeSet":           "NatTypeSet sets NAT type (values: 0, 1).",
-	"Upnp":                 "Upnp retrieves the status of UPNP.",
-	"UpnpSet":              "UpnpSet enables/disables UPNP.",
-	"PrivacyPolicy":        "Confirm privacy policy",
-	"AutoUpdate":           "Configure auto update of modem firmware",
-	"BasicDeviceInfo":      "Set basic device info to restore",
-	"OnlineUpdateInfo":     "Online update config info",
-	"OnlineUpdateConfig":   "Configure online update config",
+	"DeviceRestore":            "DeviceRestore restores a device configuration backup retrieved with DeviceBackup, by uploading it through the WebUI configuration restore form. The backup data is verified against sum, as returned by BackupChecksum, before being uploaded.  The device reboots to apply the configuration. As the WebUI does not report rejected uploads, DeviceRestore waits for the device to go down, returning ErrRestoreRejected when it does not; use WaitForDevice before using the client again.",
+	"DdnsEntries":              "DdnsEntries retrieves the DDNS entries.",
+	"DdnsCreate":               "DdnsCreate creates a DDNS entry. The entry index is assigned by the device.",
+	"DdnsUpdate":               "DdnsUpdate modifies the DDNS entry with the specified index. A masked password, as returned by DdnsEntries on some firmwares, is left unchanged.",
+	"DdnsDelete":               "DdnsDelete deletes the DDNS entry with the specified index.",
+	"DhcpSettings":             "DhcpSettings retrieves the LAN and DHCP server settings.",
+	"DhcpSettingsSet":          "DhcpSettingsSet changes the LAN and DHCP server settings, preserving any additional settings reported by the firmware.  When the LAN IP address of the device changes and the client is using the old address, the client URL is changed to the new address and the session is re-established. As the device restarts its LAN, and the host may need a new address in the new subnet, this can fail; use WaitForDevice to re-establish the session once the device is reachable again.",
+	"StaticLeases":             "StaticLeases retrieves the static DHCP leases.",
+	"StaticLeasesSet":          "StaticLeasesSet replaces the static DHCP leases. MAC addresses are validated and normalized, and duplicate MAC or IP addresses are rejected.",
+	"StaticLeaseAdd":           "StaticLeaseAdd adds a static DHCP lease, or changes the IP address of the lease when the host already has one.",
+	"StaticLeaseRemove":        "StaticLeaseRemove removes the static DHCP lease of a host.",
+	"DialupSettings":           "DialupSettings retrieves the dialup connection settings.",
+	"DialupSettingsSet":        "DialupSettingsSet changes the dialup connection settings. The current settings are retrieved and passed to update, and the result is written back to the device, preserving both the settings left untouched by update and any additional settings reported by the firmware.",
+	"FirewallSwitches":         "FirewallSwitches retrieves the firewall toggles.",
+	"FirewallSwitchesSet":      "FirewallSwitchesSet sets the firewall toggles, preserving any additional toggles reported by the firmware.",
+	"IPFilterRules":            "IPFilterRules retrieves the LAN IP filter rules, in order.",
+	"IPFilterRulesSet":         "IPFilterRulesSet replaces the LAN IP filter rules, keeping their order.",
+	"URLFilterRules":           "URLFilterRules retrieves the URL filter rules, in order.",
+	"URLFilterRulesSet":        "URLFilterRulesSet replaces the URL filter rules, keeping their order. Duplicate URLs are rejected.",
+	"GuestWlan":                "GuestWlan retrieves the guest WiFi settings. The guest network is the second SSID (GuestWlanIndex) of devices with multi-SSID support. The dedicated guest network API (api/wlan/guest-network) of some firmwares is not supported: ErrGuestWlanNotSupported is returned for other devices.",
+	"GuestWlanSet":             "GuestWlanSet changes the guest WiFi settings. ErrGuestWlanNotSupported is returned for devices without a guest network on the multi-SSID API.",
+	"GuestWlanEnable":          "GuestWlanEnable enables or disables the guest WiFi, keeping its settings.",
+	"WlanSchedule":             "WlanSchedule retrieves the weekly WiFi on/off schedule, using the WiFi time rule API (api/wlan/wifi-timerule). The WiFi time switch API (api/wlan/wlan-time-switch) of some firmwares is not supported: ErrWlanScheduleNotSupported is returned for devices without the time rule API.",
+	"WlanScheduleSet":          "WlanScheduleSet replaces the weekly WiFi on/off schedule. ErrWlanScheduleNotSupported is returned for devices without the WiFi time rule API.",
+	"WlanScheduleEnable":       "WlanScheduleEnable enables or disables the weekly WiFi on/off schedule, keeping its rules.",
+	"DoJson":                   "Do sends a request to the server with the provided path. If data is nil, then GET will be used as the HTTP method, otherwise POST will be used.",
+	"NewSessionAndTokenID":     "NewSessionAndTokenID starts a session with the server, and returns the session and token.",
+	"SetSessionAndTokenID":     "SetSessionAndTokenID sets the sessionID and tokenID for the Client.",
+	"GlobalConfig":             "GlobalConfig retrieves global Hilink configuration.",
+	"NetworkTypes":             "NetworkTypes retrieves available network types.",
+	"PCAssistantConfig":        "PCAssistantConfig retrieves PC Assistant configuration.",
+	"DeviceConfig":             "DeviceConfig retrieves device configuration.",
+	"WebUIConfig":              "WebUIConfig retrieves WebUI configuration.",
+	"SmsConfig":                "SmsConfig retrieves device SMS configuration.",
+	"WlanConfig":               "WlanConfig retrieves basic WLAN settings.",
+	"DhcpConfig":               "DhcpConfig retrieves DHCP configuration.",
+	"CradleStatusInfo":         "CradleStatusInfo retrieves cradle status information.",
+	"CradleMACSet":             "CradleMACSet sets the MAC address for the cradle.",
+	"CradleMAC":                "CradleMAC retrieves cradle MAC address.",
+	"AutorunVersion":           "AutorunVersion retrieves device autorun version.",
+	"DeviceBasicInfo":          "DeviceBasicInfo retrieves basic device information.",
+	"PublicKey":                "PublicKey retrieves webserver public key.",
+	"DeviceControl":            "DeviceControl sends a control code to the device.",
+	"DeviceReboot":             "DeviceReboot restarts the device.",
+	"DeviceReset":              "DeviceReset resets the device configuration.",
+	"DeviceBackup":             "DeviceBackup backups device configuration and retrieves the backed up configuration data (the nvram.bak file).",
+	"DeviceShutdown":           "DeviceShutdown shuts down the device.",
+	"DeviceFeatures":           "DeviceFeatures retrieves device feature information.",
+	"DeviceInfo":               "DeviceInfo retrieves general device information.",
+	"DeviceModeSet":            "DeviceModeSet sets the device mode (0-project, 1-debug).",
+	"FastbootFeatures":         "FastbootFeatures retrieves fastboot feature information.",
+	"PowerFeatures":            "PowerFeatures retrieves power feature information.",
+	"TetheringFeatures":        "TetheringFeatures retrieves USB tethering feature information.",
+	"SignalInfo":               "SignalInfo retrieves network signal information.",
+	"ConnectionInfo":           "ConnectionInfo retrieves connection (dialup) information.",
+	"ConnectionProfile":        "ConnectionProfile sets the connection (dialup) roaming auto connect (\"0\" or \"1\") and max idle time (in seconds) settings. Empty values are left unchanged, as are the other dialup settings (see DialupSettingsSet).",
+	"GlobalFeatures":           "GlobalFeatures retrieves global feature information.",
+	"Language":                 "Language retrieves current language.",
+	"LanguageSet":              "LanguageSet sets the language.",
+	"NotificationInfo":         "NotificationInfo retrieves notification information.",
+	"SimInfo":                  "SimInfo retrieves SIM card information.",
+	"StatusInfo":               "StatusInfo retrieves general device status information.",
+	"TrafficInfo":              "TrafficInfo retrieves traffic statistic information.",
+	"TrafficClear":             "TrafficClear clears the current traffic statistics.",
+	"MonthInfo":                "MonthInfo retrieves the month download statistic information.",
+	"WlanMonthInfo":            "WlanMonthInfo retrieves the WLAN month download statistic information.",
+	"NetworkInfo":              "NetworkInfo retrieves network provider information.",
+	"WifiFeatures":             "WifiFeatures retrieves wifi feature information.",
+	"ModeList":                 "ModeList retrieves available network modes.",
+	"ModeInfo":                 "ModeInfo retrieves network mode settings information.",
+	"ModeNetworkInfo":          "ModeNetworkInfo retrieves current network mode information.",
+	"ModeSet":                  "ModeSet sets the network mode.",
+	"NetworkRegisterInfo":      "NetworkRegisterInfo retrieves network registration (PLMN selection) information.",
+	"NetworkRegister":          "NetworkRegister (re-)registers the device with a network provider (mode values: 0-auto, 1-manual). The plmn and rat are only used in manual mode.",
+	"PinInfo":                  "PinInfo retrieves SIM PIN status information.",
+	"PinEnter":                 "PinEnter enters a SIM PIN.",
+	"PinActivate":              "PinActivate activates a SIM PIN.",
+	"PinDeactivate":            "PinDeactivate deactivates a SIM PIN.",
+	"PinChange":                "PinChange changes a SIM PIN.",
+	"PinEnterPuk":              "PinEnterPuk enters a SIM PIN puk.",
+	"PinSaveInfo":              "PinSaveInfo retrieves SIM PIN save information.",
+	"PinSimlockInfo":           "PinSimlockInfo retrieves SIM lock information.",
+	"MobileDataSwitch":         "",
+	"MobileDataSwitchState":    "",
+	"MobileDataActivate":       "",
+	"MobileDataDeactivate":     "",
+	"Connect":                  "Connect connects the Hilink device to the network provider.",
+	"Disconnect":               "Disconnect disconnects the Hilink device from the network provider.",
+	"ProfileInfo":              "ProfileInfo retrieves profile information (ie, APN).",
+	"ProfileAdd":               "ProfileAdd adds a connection profile using PDP type IPv4 and no authentication. See ProfileCreate for the other profile settings.",
+	"ProfileDelete":            "ProfileDelete deletes a connection profile, setting newDefault as the default profile (\"0\" when there is no profile left). See ProfileRemove for the typed variant.",
+	"SmsFeatures":              "SmsFeatures retrieves SMS feature information.",
+	"SmsList":                  "SmsList retrieves list of SMS in an inbox.",
+	"SmsCount":                 "SmsCount retrieves count of SMS per inbox type.",
+	"SmsSend":                  "SmsSend sends an SMS.",
+	"SmsSendStatus":            "SmsSendStatus retrieves SMS send status information.",
+	"SmsReadSet":               "SmsReadSet sets the read status of a SMS.",
+	"SmsDelete":                "SmsDelete deletes a specified SMS.",
+	"UssdStatus":               "UssdStatus retrieves current USSD session status information.",
+	"UssdCode":                 "UssdCode sends a USSD code to the Hilink device.",
+	"UssdContent":              "UssdContent retrieves content buffer of the active USSD session.",
+	"UssdRelease":              "UssdRelease releases the active USSD session.",
+	"DdnsList":                 "DdnsList retrieves list of DDNS providers.",
+	"LogPath":                  "LogPath retrieves device log path (URL).",
+	"LogInfo":                  "LogInfo retrieves current log setting information.",
+	"PhonebookGroupList":       "PhonebookGroupList retrieves list of the phonebook groups.",
+	"PhonebookCount":           "PhonebookCount retrieves count of phonebook entries per group.",
+	"PhonebookImport":          "PhonebookImport imports SIM contacts into specified phonebook group.",
+	"PhonebookDelete":          "PhonebookDelete deletes a specified phonebook entry.",
+	"PhonebookList":            "PhonebookList retrieves list of phonebook entries from a specified group.",
+	"PhonebookCreate":          "PhonebookCreate creates a new phonebook entry.",
+	"FirewallFeatures":         "FirewallFeatures retrieves firewall security feature information.",
+	"DmzConfig":                "DmzConfig retrieves DMZ status and IP address of DMZ host.",
+	"DmzConfigSet":             "DmzConfigSet enables or disables the DMZ and the DMZ IP address of the device.",
+	"SipAlg":                   "SipAlg retrieves status and port of the SIP application-level gateway.",
+	"SipAlgSet":                "SipAlgSet enables/disables SIP application-level gateway and sets SIP port.",
+	"NatType":                  "NatType retrieves NAT type.",
+	"NatTypeSet":               "NatTypeSet sets NAT type (values: 0, 1).",
+	"Upnp":                     "Upnp retrieves the status of UPNP.",
+	"UpnpSet":                  "UpnpSet enables/disables UPNP.",
+	"PrivacyPolicy":            "Confirm privacy policy",
+	"AutoUpdate":               "Configure auto update of modem firmware",
+	"BasicDeviceInfo":          "Set basic device info to restore",
+	"OnlineUpdateConfig":       "Configure online update config",
+	"OnlineUpdateInfo":         "Online update config info",
+	"HostList":                 "HostList retrieves the hosts connected to the device. On firmware without the LAN host information API, only WiFi hosts are reported.",
+	"MacFilter":                "MacFilter retrieves the WiFi MAC filter.",
+	"MacFilterSet":             "MacFilterSet replaces the WiFi MAC filter. MAC addresses are validated and normalized, and duplicates are rejected.",
+	"MacFilterModeSet":         "MacFilterModeSet sets the WiFi MAC filter mode (\"disabled\", \"allow\" or \"deny\"), keeping the entries.",
+	"MacFilterAdd":             "MacFilterAdd adds a host to the WiFi MAC filter, or updates its hostname when already present.",
+	"MacFilterRemove":          "MacFilterRemove removes a host from the WiFi MAC filter.",
+	"PortForwards":             "PortForwards retrieves the port forwarding rules.",
+	"PortForwardsSet":          "PortForwardsSet replaces the port forwarding rules. The rules are validated, and rules with overlapping WAN ports for the same protocol are rejected.",
+	"PortForwardAdd":           "PortForwardAdd adds a port forwarding rule. Only the new rule is validated, the existing rules are written back as retrieved from the device.",
+	"PortForwardUpdate":        "PortForwardUpdate replaces the port forwarding rule at index. Only the new rule is validated, the other rules are written back as retrieved from the device.",
+	"PortForwardDelete":        "PortForwardDelete removes the port forwarding rule at index.",
+	"ProfileList":              "ProfileList retrieves the connection profiles.",
+	"ProfileByName":            "ProfileByName retrieves the connection profile with the specified name.",
+	"ProfileByIndex":           "ProfileByIndex retrieves the connection profile with the specified index.",
+	"ProfileCreate":            "ProfileCreate creates a new connection profile, making it the default profile when p.IsDefault is set. The profile index is assigned by the device.",
+	"ProfileUpdate":            "ProfileUpdate modifies the connection profile with the specified index, making it the default profile when p.IsDefault is set. A masked password, as returned by ProfileList on some firmwares, is left unchanged.",
+	"ProfileRemove":            "ProfileRemove deletes the connection profile with the specified index, setting newDefault as the default profile (0 when there is no profile left).",
+	"ProfileSetDefault":        "ProfileSetDefault sets the default connection profile.",
+	"Provision":                "Provision configures the connection profile for the inserted SIM card using the APN database. The profile is only changed when the default profile differs from the database entry: an existing profile with the same APN settings is selected as default, otherwise a new default profile is created.",
+	"StaticRoutes":             "StaticRoutes retrieves the static routes.",
+	"StaticRoutesSet":          "StaticRoutesSet replaces the static routes. Duplicate destinations are rejected.",
+	"StaticRouteAdd":           "StaticRouteAdd adds an enabled static route.",
+	"StaticRouteRemove":        "StaticRouteRemove removes the static route for a destination network.",
+	"WanDNS":                   "WanDNS retrieves the DNS servers used on the WAN connection.",
+	"LanDNSSet":                "LanDNSSet sets the DNS servers handed out to the LAN clients by the DHCP server: primary and secondary are handed out instead of the device itself, which relays DNS requests to the servers of the WAN connection. An empty primary server restores relaying. The DNS servers of the WAN connection are left untouched.  Devices without the DHCP settings API return the ErrorCodeNotSupported error.",
+	"Signal":                   "Signal retrieves the signal information as a Signal.",
+	"Snapshot":                 "Snapshot retrieves a snapshot of the device state, calling every getter of the client that takes no parameter and returns XMLData. A getter failing does not abort the snapshot; an error is only returned when no getter succeeded.",
+	"SpecialApplications":      "SpecialApplications retrieves the special application (port triggering) rules.",
+	"SpecialApplicationsSet":   "SpecialApplicationsSet replaces the special application (port triggering) rules. The rules are validated.",
+	"SpecialApplicationAdd":    "SpecialApplicationAdd adds an enabled special application (port triggering) rule. Protocols are \"tcp\", \"udp\" or \"both\", and openPorts is a port or a port range (\"5000-5010\"). Only the new rule is validated, the existing rules are written back as retrieved from the device.",
+	"SpecialApplicationRemove": "SpecialApplicationRemove removes the special application (port triggering) rule at index. The other rules are written back as retrieved from the device.",
+	"Status":                   "Status retrieves general device status information as a Status.",
+	"TrafficStats":             "TrafficStats retrieves the traffic statistics.",
+	"MonthStats":               "MonthStats retrieves the traffic statistics of the current billing cycle.",
+	"DataPlan":                 "DataPlan retrieves the data plan configuration.",
+	"DataPlanSet":              "DataPlanSet sets the data plan configuration. The data limit is rounded down to whole megabytes.",
+	"WaitForDevice":            "WaitForDevice waits until the WebUI answers again and a fresh session has been created on the client.",
+	"WaitForRegistration":      "WaitForRegistration waits until the SIM is ready and the device is registered with a network.",
+	"WaitForConnected":         "WaitForConnected waits until the device has an established connection with a WAN IP address.",
+	"WaitForRestart":           "WaitForRestart waits for the device to go down after a reboot was requested (eg, by DeviceReboot), then waits until it is available again and re-establishes the session on the client.",
+	"RebootAndWait":            "RebootAndWait restarts the device, waits until it is available again, and re-establishes the session on the client.",
+	"WlanSettings":             "WlanSettings retrieves the settings of the WiFi networks.",
+	"WlanSettingsSet":          "WlanSettingsSet changes the settings of the WiFi network with the specified SSID index. The current settings are retrieved and passed to update, and the result is written back to the device, preserving any additional settings reported by the firmware. When required by the device, the security settings (including the key) are sent encrypted. ErrWlanKeyMasked is returned when the firmware reports masked keys, as they would be written back as is. As Band is read-only, changing it returns ErrInvalidValue.  The WiFi is restarted by the device, dropping connected clients.",
+	"WpsInfo":                  "WpsInfo retrieves WPS information.",
+	"WpsState":                 "WpsState retrieves the WPS state of the primary WiFi network.",
+	"WpsPushButton":            "WpsPushButton triggers WPS push-button mode, allowing a client to join during the WPS window.",
+	"WpsPushButtonAndWait":     "WpsPushButtonAndWait triggers WPS push-button mode and waits until a new client joins the WiFi, returning the client. ErrWpsTimeout is returned when no client joined during the WPS window.",
+	"WpsAPPin":                 "WpsAPPin retrieves the WPS PIN of the device (the AP PIN).",
+	"WpsAPPinSet":              "WpsAPPinSet sets the WPS PIN of the device (the AP PIN).",
+	"WpsAPPinGenerate":         "WpsAPPinGenerate sets a new random WPS PIN on the device, returning the PIN.",
 }
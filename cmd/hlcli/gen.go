@@ -12,6 +12,8 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -39,12 +41,23 @@ func main() {
 		log.Fatalf("invalid package name %s", pkgName)
 	}
 
+	// sort files, for reproducible output
+	var files []*ast.File
+	var names []string
+	for n := range pkgs[pkgName].Files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		files = append(files, pkgs[pkgName].Files[n])
+	}
+
 	buf := new(bytes.Buffer)
 	buf.WriteString(hdr)
 
 	buf.WriteString("var methodParamMap = map[string][]string{\n")
 
-	for _, f := range pkgs[pkgName].Files {
+	for _, f := range files {
 		for _, d := range f.Decls {
 			fd, typ, ok := getRecvType(d)
 			if !ok || typ != "Client" || !fd.Name.IsExported() || fd.Name.Name == "Do" {
@@ -64,14 +77,14 @@ func main() {
 	buf.WriteString("}\n\n")
 
 	buf.WriteString("var methodCommentMap = map[string]string{\n")
-	for _, f := range pkgs[pkgName].Files {
+	for _, f := range files {
 		for _, d := range f.Decls {
 			fd, typ, ok := getRecvType(d)
 			if !ok || typ != "Client" || !fd.Name.IsExported() || fd.Name.Name == "Do" {
 				continue
 			}
 
-			str := `"` + fd.Name.Name + `": "` + strings.TrimSpace(strings.Replace(fd.Doc.Text(), "\n", " ", -1)) + "\",\n"
+			str := strconv.Quote(fd.Name.Name) + `: ` + strconv.Quote(strings.TrimSpace(strings.Replace(fd.Doc.Text(), "\n", " ", -1))) + ",\n"
 			buf.WriteString(str)
 		}
 	}
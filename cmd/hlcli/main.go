@@ -41,6 +41,29 @@ var (
 	errorInterface = reflect.TypeOf((*error)(nil)).Elem()
 )
 
+// isCallable determines if the method can be called from the command line:
+// it must return 2 results, the last being an error, and only take parameters
+// that can be passed as flags.
+func isCallable(m reflect.Method) bool {
+	if m.Type.NumOut() != 2 || !m.Type.Out(1).Implements(errorInterface) {
+		return false
+	}
+
+	for i := 1; i < m.Type.NumIn(); i++ {
+		p := m.Type.In(i)
+		switch p.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Uint, reflect.String:
+		default:
+			// special ...string case
+			if !(m.Type.IsVariadic() && i == m.Type.NumIn()-1 && p.Elem().Kind() == reflect.String) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 func findMethodNum(typ reflect.Type, methodName string) int {
 	found := false
 	methodNum := 0
@@ -48,8 +71,8 @@ func findMethodNum(typ reflect.Type, methodName string) int {
 	for i := 0; i < typ.NumMethod(); i++ {
 		m := typ.Method(i)
 
-		// skip if results != 2 or if last result is not error
-		if m.Type.NumOut() != 2 || !m.Type.Out(1).Implements(errorInterface) {
+		// skip if not callable from the command line
+		if !isCallable(m) {
 			continue
 		}
 
@@ -88,8 +111,8 @@ Where <method> is one of the following:
 	for i := 0; i < len(methods); i++ {
 		m := methods[i]
 
-		// skip if results != 2 or if last result is not error
-		if m.Type.NumOut() != 2 || !m.Type.Out(1).Implements(errorInterface) {
+		// skip if not callable from the command line
+		if !isCallable(m) {
 			continue
 		}
 
@@ -106,6 +129,10 @@ Note that method names are case-insensitive.
 For help regarding the available parameters for a method:
 
 	`+os.Args[0]+` help <method>
+
+To apply a configuration document (YAML or JSON) to the device:
+
+	`+os.Args[0]+` apply [-n] <file>
 `)
 }
 
@@ -156,6 +183,12 @@ func main() {
 		return
 	}
 
+	// apply is not a client method
+	if os.Args[1] == "apply" {
+		doApply(os.Args[2:])
+		return
+	}
+
 	// find method
 	typ := reflect.TypeOf(&hilink.Client{})
 	methodNum := findMethodNum(typ, os.Args[1])
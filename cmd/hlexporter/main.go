@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jpunie/hilink"
+)
+
+var (
+	flagListen   = flag.String("listen", ":9103", "listen address")
+	flagEndpoint = flag.String("endpoint", "http://192.168.8.1/", "api endpoints (comma separated)")
+	flagUser     = flag.String("user", "", "username")
+	flagPass     = flag.String("pass", "", "password")
+	flagDebug    = flag.Bool("v", false, "enable verbose")
+)
+
+func main() {
+	flag.Parse()
+
+	var devices []*device
+	for _, e := range strings.Split(*flagEndpoint, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			devices = append(devices, &device{endpoint: e, stats: newAPIStats()})
+		}
+	}
+	if len(devices) == 0 {
+		fmt.Fprintln(os.Stderr, "error: no endpoint")
+		os.Exit(1)
+	}
+
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
+		// scrape devices concurrently
+		regs := make([]*registry, len(devices))
+		var wg sync.WaitGroup
+		for i, d := range devices {
+			wg.Add(1)
+			go func(i int, d *device) {
+				defer wg.Done()
+				regs[i] = newRegistry()
+				d.collect(regs[i])
+			}(i, d)
+		}
+		wg.Wait()
+
+		// merge families
+		r := newRegistry()
+		for _, z := range regs {
+			r.merge(z)
+		}
+
+		var buf bytes.Buffer
+		if _, err := r.WriteTo(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+
+	log.Printf("listening on %s", *flagListen)
+	log.Fatal(http.ListenAndServe(*flagListen, nil))
+}
+
+// device is a monitored device.
+type device struct {
+	endpoint string
+	stats    *apiStats
+	client   *hilink.Client
+	name     string
+	imei     string
+
+	// power records whether the device has a battery, once determined.
+	power *bool
+
+	sync.Mutex
+}
+
+// labels returns the labels identifying the device. Only the endpoint is
+// used, as it is known before the device could be queried; the device name
+// and IMEI are reported by the hilink_device_info metric.
+func (d *device) labels() labels {
+	return labels{"endpoint", d.endpoint}
+}
+
+// connect creates the client and retrieves the device identity, when not
+// done yet.
+func (d *device) connect() error {
+	if d.client != nil {
+		return nil
+	}
+
+	// options
+	opts := []hilink.Option{
+		hilink.URL(d.endpoint),
+		hilink.Hooks(d.stats),
+	}
+	if *flagUser != "" {
+		opts = append(opts, hilink.Auth(*flagUser, *flagPass))
+	}
+	if *flagDebug {
+		opts = append(opts, hilink.Log(log.Printf, log.Printf))
+	}
+
+	// create client
+	client, err := hilink.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+
+	info, err := client.DeviceInfo()
+	if err != nil {
+		return err
+	}
+	d.name, _ = info["DeviceName"].(string)
+	d.imei, _ = info["Imei"].(string)
+	d.client = client
+
+	return nil
+}
+
+// collect adds the metrics of the device to the registry. When the session
+// was rejected, the client is recreated and the metrics are collected again.
+// The client is recreated on the next scrape when the device cannot be
+// reached.
+func (d *device) collect(r *registry) {
+	d.Lock()
+	defer d.Unlock()
+
+	l := d.labels()
+	z, err := d.collectDevice(l)
+	if hilink.IsErrorCode(err, errorCodeSessionToken) {
+		d.client = nil
+		z, err = d.collectDevice(l)
+	}
+	if err != nil {
+		log.Printf("%s: %v", d.endpoint, err)
+		d.client = nil
+	} else {
+		r.merge(z)
+	}
+
+	r.bool("hilink_up", "Whether the device could be queried.", l, err == nil)
+	d.stats.collect(r, l)
+}
+
+// collectDevice connects to the device when needed, and returns a registry
+// with the metrics of the device.
+func (d *device) collectDevice(l labels) (*registry, error) {
+	if err := d.connect(); err != nil {
+		return nil, err
+	}
+	r := newRegistry()
+	r.gauge("hilink_device_info", "Identity of the device.", l.with("device", d.name, "imei", d.imei), 1)
+	if err := d.collectStatus(r, l); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// errorCodeSessionToken is the error code returned by the WebUI when the
+// session token was rejected, eg, once the session expired.
+const errorCodeSessionToken = "125003"
+
+// missing determines if err, as returned when retrieving the data of
+// optional metrics, only makes those metrics unavailable, rather than
+// indicating that the device cannot be queried.
+func missing(err error) bool {
+	return hilink.IsErrorCode(err, hilink.ErrorCodeNotSupported)
+}
+
+// collectStatus adds the status metrics of the device to the registry.
+func (d *device) collectStatus(r *registry, l labels) error {
+	info, err := d.client.StatusInfo()
+	if err != nil {
+		return err
+	}
+	s := hilink.ParseStatus(info)
+	r.bool("hilink_connected", "Whether the device has an established connection.", l, s.IsConnected())
+	r.gauge("hilink_connection_status", "Connection status code of the device.", l, float64(s.ConnectionStatus))
+	r.bool("hilink_registered", "Whether the device is registered with a network.", l, s.IsRegistered())
+	r.bool("hilink_roaming", "Whether the device is roaming.", l, s.IsRoaming())
+	r.gauge("hilink_network_type_info", "Current network type.", l.with("type", s.NetworkType.String(), "generation", s.NetworkType.Generation()), 1)
+	r.gauge("hilink_signal_bars", "Signal strength icon level.", l, float64(s.SignalIcon))
+
+	sig, err := d.client.Signal()
+	switch {
+	case err == nil:
+		d.collectSignal(r, l, sig)
+	case !missing(err):
+		return err
+	}
+
+	t, err := d.client.TrafficStats()
+	switch {
+	case err == nil:
+		d.collectTraffic(r, l, t)
+	case !missing(err):
+		return err
+	}
+
+	sms, err := d.client.SmsCount()
+	switch {
+	case err == nil:
+		r.gauge("hilink_sms_unread", "Number of unread SMS.", l, float64(xmlInt(sms, "LocalUnread")+xmlInt(sms, "SimUnread")))
+	case !missing(err):
+		return err
+	}
+
+	return d.collectBattery(r, l, info)
+}
+
+// collectSignal adds the signal levels reported by the device to the
+// registry.
+func (d *device) collectSignal(r *registry, l labels, sig *hilink.Signal) {
+	for _, m := range []struct {
+		name, help string
+		v          *float64
+	}{
+		{"hilink_signal_rsrp_dbm", "LTE reference signal received power.", sig.RSRP},
+		{"hilink_signal_rsrq_db", "LTE reference signal received quality.", sig.RSRQ},
+		{"hilink_signal_sinr_db", "LTE signal to interference plus noise ratio.", sig.SINR},
+		{"hilink_signal_rssi_dbm", "Received signal strength indicator.", sig.RSSI},
+		{"hilink_signal_rscp_dbm", "UMTS received signal code power.", sig.RSCP},
+		{"hilink_signal_ecio_db", "UMTS energy per chip to interference ratio.", sig.EcIo},
+	} {
+		if m.v != nil {
+			r.gauge(m.name, m.help, l, *m.v)
+		}
+	}
+}
+
+// collectTraffic adds the traffic statistics to the registry.
+func (d *device) collectTraffic(r *registry, l labels, t *hilink.TrafficStats) {
+	r.counter("hilink_traffic_upload_bytes_total", "Bytes uploaded since the statistics were cleared.", l, float64(t.TotalUpload))
+	r.counter("hilink_traffic_download_bytes_total", "Bytes downloaded since the statistics were cleared.", l, float64(t.TotalDownload))
+	r.gauge("hilink_traffic_current_upload_bytes", "Bytes uploaded on the current connection.", l, float64(t.CurrentUpload))
+	r.gauge("hilink_traffic_current_download_bytes", "Bytes downloaded on the current connection.", l, float64(t.CurrentDownload))
+	r.gauge("hilink_traffic_upload_rate_bytes", "Current upload rate, in bytes per second.", l, float64(t.CurrentUploadRate))
+	r.gauge("hilink_traffic_download_rate_bytes", "Current download rate, in bytes per second.", l, float64(t.CurrentDownloadRate))
+	r.gauge("hilink_connection_duration_seconds", "Duration of the current connection.", l, t.CurrentConnectTime.Seconds())
+}
+
+// collectBattery adds the battery level reported in the status information
+// to the registry, for devices with a battery, as indicated by PowerFeatures
+// being supported.
+func (d *device) collectBattery(r *registry, l labels, info hilink.XMLData) error {
+	if d.power == nil {
+		_, err := d.client.PowerFeatures()
+		if err != nil && !missing(err) {
+			return err
+		}
+		power := err == nil
+		d.power = &power
+	}
+	if !*d.power {
+		return nil
+	}
+
+	if v, ok := info["BatteryPercent"].(string); ok && v != "" {
+		r.gauge("hilink_battery_percent", "Battery charge level.", l, float64(xmlInt(info, "BatteryPercent")))
+	}
+	return nil
+}
+
+// xmlInt returns the integer value of the element named key, or 0.
+func xmlInt(d hilink.XMLData, key string) int {
+	s, _ := d[key].(string)
+	i, _ := strconv.Atoi(strings.TrimSpace(s))
+	return i
+}
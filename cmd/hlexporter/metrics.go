@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jpunie/hilink"
+)
+
+// labels are metric labels, as name/value pairs.
+type labels []string
+
+// with returns a copy of l with the name/value pairs added.
+func (l labels) with(pairs ...string) labels {
+	return append(append(labels(nil), l...), pairs...)
+}
+
+// String formats the labels using the Prometheus text format.
+func (l labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	var s []string
+	for i := 0; i+1 < len(l); i += 2 {
+		s = append(s, l[i]+`="`+escapeLabel(l[i+1])+`"`)
+	}
+	return "{" + strings.Join(s, ",") + "}"
+}
+
+// labelEscaper escapes label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// formatValue formats a sample value.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// family is a metric family.
+type family struct {
+	typ, help string
+	samples   []string
+}
+
+// registry collects the samples of a scrape, grouped by metric family as
+// required by the text format.
+type registry struct {
+	families map[string]*family
+}
+
+// newRegistry creates a registry.
+func newRegistry() *registry {
+	return &registry{families: make(map[string]*family)}
+}
+
+// add adds a sample. The sample name is the family name, unless the family is
+// a histogram.
+func (r *registry) add(fam, typ, help, name string, l labels, v float64) {
+	f, ok := r.families[fam]
+	if !ok {
+		f = &family{typ: typ, help: help}
+		r.families[fam] = f
+	}
+	f.samples = append(f.samples, name+l.String()+" "+formatValue(v))
+}
+
+// gauge adds a gauge sample.
+func (r *registry) gauge(name, help string, l labels, v float64) {
+	r.add(name, "gauge", help, name, l, v)
+}
+
+// counter adds a counter sample.
+func (r *registry) counter(name, help string, l labels, v float64) {
+	r.add(name, "counter", help, name, l, v)
+}
+
+// bool adds a gauge sample for a boolean value.
+func (r *registry) bool(name, help string, l labels, v bool) {
+	var f float64
+	if v {
+		f = 1
+	}
+	r.gauge(name, help, l, f)
+}
+
+// merge adds the samples of z to the registry.
+func (r *registry) merge(z *registry) {
+	for n, f := range z.families {
+		if e, ok := r.families[n]; ok {
+			e.samples = append(e.samples, f.samples...)
+		} else {
+			r.families[n] = f
+		}
+	}
+}
+
+// WriteTo writes the samples using the Prometheus text format, sorted by
+// family name.
+func (r *registry) WriteTo(w io.Writer) (int64, error) {
+	var names []string
+	for n := range r.families {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var total int64
+	for _, n := range names {
+		f := r.families[n]
+		s := fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n", n, f.help, n, f.typ)
+		s += strings.Join(f.samples, "\n") + "\n"
+		c, err := io.WriteString(w, s)
+		total += int64(c)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// latencyBuckets are the upper bounds of the API latency histogram buckets,
+// in seconds.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// pathStats are the statistics of an API path.
+type pathStats struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+	errors  map[string]uint64
+}
+
+// apiStats is a hook collecting client-side API latency and error
+// statistics.
+type apiStats struct {
+	paths map[string]*pathStats
+
+	sync.Mutex
+}
+
+// newAPIStats creates the API statistics.
+func newAPIStats() *apiStats {
+	return &apiStats{paths: make(map[string]*pathStats)}
+}
+
+// OnRequest satisfies the hilink.Hook interface.
+func (s *apiStats) OnRequest(*hilink.Event) {}
+
+// OnResponse satisfies the hilink.Hook interface.
+func (s *apiStats) OnResponse(e *hilink.Event) {
+	s.observe(e, "")
+}
+
+// OnError satisfies the hilink.Hook interface.
+func (s *apiStats) OnError(e *hilink.Event) {
+	code := e.Code
+	switch {
+	case code != "":
+	case e.Status == 0:
+		code = "transport"
+	case e.Status != 200:
+		code = "http_" + strconv.Itoa(e.Status)
+	default:
+		code = "other"
+	}
+	s.observe(e, code)
+}
+
+// observe records a completed request, and its error code when not empty.
+func (s *apiStats) observe(e *hilink.Event, code string) {
+	s.Lock()
+	defer s.Unlock()
+
+	p, ok := s.paths[e.Path]
+	if !ok {
+		p = &pathStats{
+			buckets: make([]uint64, len(latencyBuckets)),
+			errors:  make(map[string]uint64),
+		}
+		s.paths[e.Path] = p
+	}
+
+	d := e.Duration.Seconds()
+	p.count++
+	p.sum += d
+	for i, b := range latencyBuckets {
+		if d <= b {
+			p.buckets[i]++
+		}
+	}
+	if code != "" {
+		p.errors[code]++
+	}
+}
+
+// collect adds the API statistics to the registry.
+func (s *apiStats) collect(r *registry, l labels) {
+	s.Lock()
+	defer s.Unlock()
+
+	const (
+		latency     = "hilink_api_request_duration_seconds"
+		latencyHelp = "Duration of the API requests made by the exporter."
+	)
+	var paths []string
+	for path := range s.paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		p, pl := s.paths[path], l.with("path", path)
+		for i, b := range latencyBuckets {
+			r.add(latency, "histogram", latencyHelp, latency+"_bucket", pl.with("le", formatValue(b)), float64(p.buckets[i]))
+		}
+		r.add(latency, "histogram", latencyHelp, latency+"_bucket", pl.with("le", "+Inf"), float64(p.count))
+		r.add(latency, "histogram", latencyHelp, latency+"_sum", pl, p.sum)
+		r.add(latency, "histogram", latencyHelp, latency+"_count", pl, float64(p.count))
+
+		r.counter("hilink_api_requests_total", "Number of API requests made by the exporter.", pl, float64(p.count))
+		var codes []string
+		for code := range p.errors {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			r.counter("hilink_api_errors_total", "Number of failed API requests, by device error code.", pl.with("code", code), float64(p.errors[code]))
+		}
+	}
+}
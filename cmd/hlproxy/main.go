@@ -3,9 +3,11 @@ package main
 //go:generate go run gen.go
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 
-	// "flag"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -39,18 +41,53 @@ const SMS_COMMAND_UPTIME = "uptime"
 const SMS_CHECK_DELAY = 5
 const NETWORK_CHECK_DELAY = 30
 
+// ProfileRequest is a connection profile create or update request, in the
+// format of the profiles returned by the proxy, so that a retrieved profile
+// can be sent back as is. IsDefault makes the profile the default profile.
 type ProfileRequest struct {
-	Name      string `json:"Name"`
-	ApnName   string `json:"ApnName"`
-	Username  string `json:"Username"`
-	Password  string `json:"Password"`
-	IsDefault bool   `json:"IsDefault"`
+	Name         string          `json:"Name"`
+	ApnName      string          `json:"ApnName"`
+	DialupNum    string          `json:"DialupNum"`
+	Username     string          `json:"Username"`
+	Password     string          `json:"Password"`
+	AuthMode     hilink.AuthMode `json:"AuthMode"`
+	IpIsStatic   string          `json:"IpIsStatic"`
+	IpAddress    string          `json:"IpAddress"`
+	DnsIsStatic  string          `json:"DnsIsStatic"`
+	PrimaryDns   string          `json:"PrimaryDns"`
+	SecondaryDns string          `json:"SecondaryDns"`
+	PDPType      hilink.PDPType  `json:"iptype"`
+	IsDefault    bool            `json:"IsDefault"`
+}
+
+// profile converts the request into a hilink.Profile.
+func (p ProfileRequest) profile() hilink.Profile {
+	profile := hilink.Profile{
+		Name:         p.Name,
+		ApnName:      p.ApnName,
+		Username:     p.Username,
+		Password:     p.Password,
+		AuthMode:     p.AuthMode,
+		PDPType:      p.PDPType,
+		DialupNumber: p.DialupNum,
+		IsDefault:    p.IsDefault,
+	}
+	if p.IpIsStatic == "1" {
+		profile.IPAddress = p.IpAddress
+	}
+	if p.DnsIsStatic == "1" {
+		profile.PrimaryDNS = p.PrimaryDns
+		profile.SecondaryDNS = p.SecondaryDns
+	}
+	return profile
 }
 
 type ConnectionRequest struct {
-	Roaming     string `json:"Roaming"`
-	MaxIdleTime string `json:"MaxIdleTime"`
-	DataSwitch  string `json:"DataSwitch"`
+	Roaming     string              `json:"Roaming"`
+	MaxIdleTime string              `json:"MaxIdleTime"`
+	DataSwitch  string              `json:"DataSwitch"`
+	ConnectMode *hilink.ConnectMode `json:"ConnectMode"`
+	MTU         uint                `json:"MTU"`
 }
 
 type SmsRequest struct {
@@ -58,8 +95,15 @@ type SmsRequest struct {
 	Message string `json:"Message"`
 }
 
+var (
+	flagApnDatabase    = flag.String("apn-db", "", "APN database file (YAML or JSON) overriding the bundled entries")
+	flagWatchdogReboot = flag.Bool("watchdog-reboot", false, "let the watchdog reboot the modem when all other remediation steps failed")
+)
+
 var hlc *hilink.Client
 
+var apnDatabase *hilink.ApnDatabase
+
 func getHilinkClient() (*hilink.Client, error) {
 	if hlc != nil {
 		return hlc, nil
@@ -117,6 +161,46 @@ func getProfileInfo(w http.ResponseWriter, r *http.Request) {
 	getJsonEncoder(w).Encode(profileInfo)
 }
 
+// profileMaps retrieves the connection profiles as returned by the device,
+// which is the format of the profiles returned by the proxy, and the current
+// profile index.
+func profileMaps(client *hilink.Client) ([]map[string]interface{}, string, error) {
+	profileInfo, err := client.ProfileInfo()
+	if err != nil {
+		return nil, "", err
+	}
+	current, _ := profileInfo["CurrentProfile"].(string)
+
+	var profiles []map[string]interface{}
+	wrapper, _ := profileInfo["Profiles"].(map[string]interface{})
+	switch v := wrapper["Profile"].(type) {
+	case map[string]interface{}:
+		profiles = append(profiles, v)
+	case []interface{}:
+		for _, element := range v {
+			if m, ok := element.(map[string]interface{}); ok {
+				profiles = append(profiles, m)
+			}
+		}
+	}
+	return profiles, current, nil
+}
+
+// profileMapWith retrieves the connection profile whose key element has the
+// specified value, as returned by the device, or nil.
+func profileMapWith(client *hilink.Client, key, value string) (map[string]interface{}, error) {
+	profiles, _, err := profileMaps(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		if profile[key] == value {
+			return profile, nil
+		}
+	}
+	return nil, nil
+}
+
 func getCurrentProfile(w http.ResponseWriter, r *http.Request) {
 	client, err := getHilinkClient()
 	if err != nil {
@@ -124,20 +208,20 @@ func getCurrentProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	profileInfo, err := client.ProfileInfo()
+	profiles, current, err := profileMaps(client)
 	if err != nil {
 		resetHilinkClient()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	profileIndex := profileInfo["CurrentProfile"].(string)
-	if profileIndex == "0" {
-		w.WriteHeader(http.StatusNotFound)
-		return
+	for _, profile := range profiles {
+		if current != "0" && profile["Index"] == current {
+			getJsonEncoder(w).Encode(profile)
+			return
+		}
 	}
-	var profiles = profileInfo["Profiles"].(map[string]interface{})["Profile"]
-	getJsonEncoder(w).Encode(getProfileWithIndexFromProfiles(profiles, profileIndex))
+	w.WriteHeader(http.StatusNotFound)
 }
 
 func listProfiles(w http.ResponseWriter, r *http.Request) {
@@ -146,27 +230,31 @@ func listProfiles(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	profileInfo, err := client.ProfileInfo()
+	profiles, _, err := profileMaps(client)
 	if err != nil {
 		resetHilinkClient()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	profilesWrapper := profileInfo["Profiles"]
+	if profiles == nil {
+		profiles = []map[string]interface{}{}
+	}
 	w.WriteHeader(http.StatusOK)
-	if reflect.TypeOf(profilesWrapper).Kind() == reflect.String {
-		getJsonEncoder(w).Encode([]int{})
-		return
-	} else {
-		profiles := profilesWrapper.(map[string]interface{})["Profile"]
-		if reflect.TypeOf(profiles).Kind() == reflect.Map {
-			s := make([]map[string]interface{}, 1, 1)
-			s[0] = profiles.(map[string]interface{})
-			getJsonEncoder(w).Encode(s)
-		} else {
-			getJsonEncoder(w).Encode(profiles)
-		}
+	getJsonEncoder(w).Encode(profiles)
+}
+
+func parseIndex(w http.ResponseWriter, r *http.Request, name string) (uint, bool) {
+	index, err := strconv.ParseUint(mux.Vars(r)["index"], 10, 32)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid %s index", name), http.StatusBadRequest)
+		return 0, false
 	}
+	return uint(index), true
+}
+
+func writeProfileNotFound(w http.ResponseWriter, profileIndex uint) {
+	w.WriteHeader(http.StatusNotFound)
+	getJsonEncoder(w).Encode(map[string]string{"message": fmt.Sprintf("Profile with index %d not found", profileIndex)})
 }
 
 func getProfile(w http.ResponseWriter, r *http.Request) {
@@ -175,35 +263,39 @@ func getProfile(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	var profileIndex = mux.Vars(r)["index"]
-	profileInfo, err := client.ProfileInfo()
+	profileIndex, ok := parseIndex(w, r, "profile")
+	if !ok {
+		return
+	}
+	profile, err := profileMapWith(client, "Index", fmt.Sprintf("%d", profileIndex))
 	if err != nil {
 		resetHilinkClient()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-
-	var profiles = profileInfo["Profiles"].(map[string]interface{})["Profile"]
-	if reflect.TypeOf(profiles).Kind() == reflect.Slice {
-		for _, element := range profiles.([]interface{}) {
-			if element.(map[string]interface{})["Index"] == profileIndex {
-				getJsonEncoder(w).Encode(element)
-				return
-			}
-		}
+	if profile == nil {
+		writeProfileNotFound(w, profileIndex)
+		return
 	}
-	w.WriteHeader(http.StatusNotFound)
-	getJsonEncoder(w).Encode(map[string]string{"message": fmt.Sprintf("Profile with index %s not found", profileIndex)})
+	getJsonEncoder(w).Encode(profile)
 }
 
 func createNewProfileFromRequest(client *hilink.Client, newProfile ProfileRequest) (bool, error) {
-	return client.ProfileAdd(
-		newProfile.Name,
-		newProfile.ApnName,
-		newProfile.Username,
-		newProfile.Password,
-		newProfile.IsDefault,
-	)
+	return client.ProfileCreate(newProfile.profile())
+}
+
+func readJsonRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	err = json.Unmarshal(reqBody, v)
+	if err != nil {
+		http.Error(w, "Error while parsing request body", http.StatusBadRequest)
+		return false
+	}
+	return true
 }
 
 func createProfile(w http.ResponseWriter, r *http.Request) {
@@ -213,144 +305,176 @@ func createProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var newProfile ProfileRequest
-	reqBody, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !readJsonRequest(w, r, &newProfile) {
 		return
 	}
-	err = json.Unmarshal(reqBody, &newProfile)
+
+	flag, err := createNewProfileFromRequest(client, newProfile)
+
 	if err != nil {
-		http.Error(w, "Error while parsing request body", http.StatusBadRequest)
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	flag, err := createNewProfileFromRequest(client, newProfile)
-
 	if !flag {
 		http.Error(w, "Call returned with failure", http.StatusInternalServerError)
 		return
 	}
 
+	profile, err := profileMapWith(client, "Name", newProfile.Name)
 	if err != nil {
-		resetHilinkClient()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		fmt.Fprintf(os.Stderr, "Failed to load profiles, error: %v\n", err)
 	}
-
 	w.WriteHeader(http.StatusCreated)
-	getJsonEncoder(w).Encode(getProfileWithName(client, newProfile.Name))
+	getJsonEncoder(w).Encode(profile)
 }
 
-func getProfileWithName(client *hilink.Client, name string) map[string]interface{} {
-	profileInfo, err := client.ProfileInfo()
+func updateProfile(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
 	if err != nil {
-		resetHilinkClient()
-		fmt.Fprintf(os.Stderr, "Failed to load profiles, error: %v\n", err)
-		return nil
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	var profiles = profileInfo["Profiles"].(map[string]interface{})["Profile"]
-	if reflect.TypeOf(profiles).Kind() == reflect.Slice {
-		for _, element := range profiles.([]interface{}) {
-			if element.(map[string]interface{})["Name"] == name {
-				return element.(map[string]interface{})
-			}
-		}
+	profileIndex, ok := parseIndex(w, r, "profile")
+	if !ok {
+		return
 	}
-	if reflect.TypeOf(profiles).Kind() == reflect.Map {
-		if profiles.(map[string]interface{})["Name"] == name {
-			return profiles.(map[string]interface{})
-		}
+	var profile ProfileRequest
+	if !readJsonRequest(w, r, &profile) {
+		return
 	}
-	return nil
-}
 
-func getProfileWithIndex(client *hilink.Client, index string) map[string]interface{} {
-	profileInfo, err := client.ProfileInfo()
+	flag, err := client.ProfileUpdate(profileIndex, profile.profile())
+	if err == hilink.ErrProfileNotFound {
+		writeProfileNotFound(w, profileIndex)
+		return
+	}
+	if err == hilink.ErrProfileReadOnly {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !flag {
+		http.Error(w, "Call returned with failure", http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := profileMapWith(client, "Index", fmt.Sprintf("%d", profileIndex))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load profiles, error: %v\n", err)
-		return nil
 	}
-	var profiles = profileInfo["Profiles"].(map[string]interface{})["Profile"]
-	return getProfileWithIndexFromProfiles(profiles, index)
+	getJsonEncoder(w).Encode(updated)
 }
 
-func getProfileWithIndexFromProfiles(profiles interface{}, index string) map[string]interface{} {
-	if reflect.TypeOf(profiles).Kind() == reflect.Slice {
-		for _, element := range profiles.([]interface{}) {
-			if element.(map[string]interface{})["Index"] == index {
-				return element.(map[string]interface{})
-			}
-		}
+func setDefaultProfile(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	if reflect.TypeOf(profiles).Kind() == reflect.Map {
-		if profiles.(map[string]interface{})["Index"] == index {
-			return profiles.(map[string]interface{})
-		}
+	profileIndex, ok := parseIndex(w, r, "profile")
+	if !ok {
+		return
+	}
+	if _, err := client.ProfileByIndex(profileIndex); err == hilink.ErrProfileNotFound {
+		writeProfileNotFound(w, profileIndex)
+		return
 	}
-	return nil
-}
 
-func deleteProfile(w http.ResponseWriter, r *http.Request) {
-	client, err := getHilinkClient()
+	flag, err := client.ProfileSetDefault(profileIndex)
 	if err != nil {
+		resetHilinkClient()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	flag, err := deleteProfileWithIndex(client, mux.Vars(r)["index"])
 	if !flag {
 		http.Error(w, "Call returned with failure", http.StatusInternalServerError)
 		return
 	}
+	w.WriteHeader(http.StatusOK)
+}
 
+func deleteProfile(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	profileIndex, ok := parseIndex(w, r, "profile")
+	if !ok {
+		return
+	}
+	flag, err := deleteProfileWithIndex(client, profileIndex)
+	if err == hilink.ErrProfileNotFound {
+		writeProfileNotFound(w, profileIndex)
+		return
+	}
 	if err != nil {
 		resetHilinkClient()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	if !flag {
+		http.Error(w, "Call returned with failure", http.StatusInternalServerError)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
-func deleteProfileWithIndex(client *hilink.Client, profileIndex string) (bool, error) {
-	profileInfo, err := client.ProfileInfo()
+func deleteProfileWithIndex(client *hilink.Client, profileIndex uint) (bool, error) {
+	profiles, err := client.ProfileList()
 	if err != nil {
 		return false, err
 	}
 
-	var currentProfile string = profileInfo["CurrentProfile"].(string)
-	if currentProfile == profileIndex {
-		currentProfile = "1"
-	}
-
-	var profiles = profileInfo["Profiles"].(map[string]interface{})["Profile"]
-	var profileCount int = 1
-	if reflect.TypeOf(profiles).Kind() == reflect.Slice {
-		profileCount = len(profiles.([]interface{}))
+	// keep the current default, or fall back to the first remaining profile
+	var found bool
+	var newDefault uint
+	for _, profile := range profiles {
+		if profile.Index == profileIndex {
+			found = true
+			continue
+		}
+		if profile.IsDefault || newDefault == 0 {
+			newDefault = profile.Index
+		}
 	}
-	if profileCount == 1 {
-		currentProfile = "0"
+	if !found {
+		return false, hilink.ErrProfileNotFound
 	}
 
-	return client.ProfileDelete(
-		profileIndex,
-		currentProfile,
-	)
+	return client.ProfileRemove(profileIndex, newDefault)
 }
 
 func remapConnectionInfo(connectionInfo, dataswitch, statusInfo map[string]interface{}) map[string]string {
+	status := hilink.ParseStatus(statusInfo)
 	// json output will sort this data, please also keep this list sorted for clarity
 	return map[string]string{
-		"ConnectionStatus":   statusInfo["ConnectionStatus"].(string),
-		"CurrentNetworkType": statusInfo["CurrentNetworkType"].(string),
-		"DataSwitch":         dataswitch["dataswitch"].(string),
-		"MaxIdleTime":        connectionInfo["MaxIdelTime"].(string),
-		"Roaming":            connectionInfo["RoamAutoConnectEnable"].(string),
-		"RoamingStatus":      statusInfo["RoamingStatus"].(string),
-		"ServiceStatus":      statusInfo["ServiceStatus"].(string),
-		"SimStatus":          statusInfo["SimStatus"].(string),
+		"ConnectionStatus":       statusInfo["ConnectionStatus"].(string),
+		"ConnectionStatusText":   status.ConnectionStatus.String(),
+		"CurrentNetworkType":     statusInfo["CurrentNetworkType"].(string),
+		"CurrentNetworkTypeText": status.NetworkType.String(),
+		"DataSwitch":             dataswitch["dataswitch"].(string),
+		"MaxIdleTime":            connectionInfo["MaxIdelTime"].(string),
+		"Roaming":                connectionInfo["RoamAutoConnectEnable"].(string),
+		"RoamingStatus":          statusInfo["RoamingStatus"].(string),
+		"RoamingStatusText":      status.RoamingStatus.String(),
+		"ServiceStatus":          statusInfo["ServiceStatus"].(string),
+		"ServiceStatusText":      status.ServiceStatus.String(),
+		"SimStatus":              statusInfo["SimStatus"].(string),
+		"SimStatusText":          status.SimStatus.String(),
 	}
 }
 
 func connectionInfoAsSlice(connectionInfo, dataswitch, statusInfo map[string]interface{}) []string {
+	status := hilink.ParseStatus(statusInfo)
+	// raw codes first, keeping the field positions; names are appended
 	return []string{
 		statusInfo["ConnectionStatus"].(string),
 		statusInfo["CurrentNetworkType"].(string),
@@ -360,6 +484,11 @@ func connectionInfoAsSlice(connectionInfo, dataswitch, statusInfo map[string]int
 		statusInfo["RoamingStatus"].(string),
 		statusInfo["ServiceStatus"].(string),
 		statusInfo["SimStatus"].(string),
+		status.ConnectionStatus.String(),
+		status.NetworkType.String(),
+		status.RoamingStatus.String(),
+		status.ServiceStatus.String(),
+		status.SimStatus.String(),
 	}
 }
 
@@ -411,33 +540,55 @@ func setConnectionInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	flag, err := client.ConnectionProfile(
-		connectionRequest.Roaming,
-		connectionRequest.MaxIdleTime,
-	)
-
-	if !flag {
-		http.Error(w, "Call returned with failure", http.StatusInternalServerError)
-		return
+	var maxIdleTime uint64
+	if connectionRequest.MaxIdleTime != "" {
+		maxIdleTime, err = strconv.ParseUint(connectionRequest.MaxIdleTime, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid MaxIdleTime", http.StatusBadRequest)
+			return
+		}
 	}
 
+	// only change the settings present in the request
+	flag, err := client.DialupSettingsSet(func(s *hilink.DialupSettings) {
+		if connectionRequest.Roaming != "" {
+			s.RoamingAutoConnect = connectionRequest.Roaming == "1"
+		}
+		if connectionRequest.MaxIdleTime != "" {
+			s.MaxIdleTime = uint(maxIdleTime)
+		}
+		if connectionRequest.ConnectMode != nil {
+			s.ConnectMode = *connectionRequest.ConnectMode
+		}
+		if connectionRequest.MTU != 0 {
+			s.MTU = connectionRequest.MTU
+		}
+	})
+
 	if err != nil {
 		resetHilinkClient()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	flag, err = client.MobileDataSwitchState(connectionRequest.DataSwitch)
-
 	if !flag {
 		http.Error(w, "Call returned with failure", http.StatusInternalServerError)
 		return
 	}
 
-	if err != nil {
-		resetHilinkClient()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if connectionRequest.DataSwitch != "" {
+		flag, err = client.MobileDataSwitchState(connectionRequest.DataSwitch)
+
+		if err != nil {
+			resetHilinkClient()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !flag {
+			http.Error(w, "Call returned with failure", http.StatusInternalServerError)
+			return
+		}
 	}
 
 	connectionInfo, err := client.ConnectionInfo()
@@ -528,6 +679,173 @@ func disconnect(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func listHosts(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	hosts, err := client.HostList()
+	if err != nil {
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if hosts == nil {
+		hosts = []hilink.Host{}
+	}
+	w.WriteHeader(http.StatusOK)
+	getJsonEncoder(w).Encode(hosts)
+}
+
+func getWps(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	state, err := client.WpsState()
+	if err != nil {
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	getJsonEncoder(w).Encode(state)
+}
+
+func startWps(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	host, err := client.WpsPushButtonAndWait(r.Context())
+	if err == hilink.ErrWpsTimeout {
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		return
+	}
+	if err != nil {
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	getJsonEncoder(w).Encode(host)
+}
+
+func listPortForwards(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rules, err := client.PortForwards()
+	if err != nil {
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	getJsonEncoder(w).Encode(rules)
+}
+
+func writePortForwardResult(w http.ResponseWriter, client *hilink.Client, flag bool, err error, status int) {
+	switch err {
+	case nil:
+	case hilink.ErrPortForwardNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case hilink.ErrInvalidValue, hilink.ErrInvalidIPAddress, hilink.ErrPortForwardOverlap:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !flag {
+		http.Error(w, "Call returned with failure", http.StatusInternalServerError)
+		return
+	}
+
+	rules, err := client.PortForwards()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load port forwards, error: %v\n", err)
+	}
+	w.WriteHeader(status)
+	getJsonEncoder(w).Encode(rules)
+}
+
+func createPortForward(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var rule hilink.PortForward
+	if !readJsonRequest(w, r, &rule) {
+		return
+	}
+	flag, err := client.PortForwardAdd(rule)
+	writePortForwardResult(w, client, flag, err, http.StatusCreated)
+}
+
+func getPortForward(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	index, ok := parseIndex(w, r, "port forward")
+	if !ok {
+		return
+	}
+	rules, err := client.PortForwards()
+	if err != nil {
+		resetHilinkClient()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if index >= uint(len(rules)) {
+		http.Error(w, hilink.ErrPortForwardNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	getJsonEncoder(w).Encode(rules[index])
+}
+
+func updatePortForward(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	index, ok := parseIndex(w, r, "port forward")
+	if !ok {
+		return
+	}
+	var rule hilink.PortForward
+	if !readJsonRequest(w, r, &rule) {
+		return
+	}
+	flag, err := client.PortForwardUpdate(index, rule)
+	writePortForwardResult(w, client, flag, err, http.StatusOK)
+}
+
+func deletePortForward(w http.ResponseWriter, r *http.Request) {
+	client, err := getHilinkClient()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	index, ok := parseIndex(w, r, "port forward")
+	if !ok {
+		return
+	}
+	flag, err := client.PortForwardDelete(index)
+	writePortForwardResult(w, client, flag, err, http.StatusOK)
+}
+
 func listSmsbox(w http.ResponseWriter, r *http.Request, boxType hilink.SmsBoxType) {
 	client, err := getHilinkClient()
 	if err != nil {
@@ -714,7 +1032,7 @@ func handleSetApnSms(client *hilink.Client, message map[string]interface{}) {
 		return
 	}
 
-	client.ConnectionProfile("1", "3600")
+	configureDialup(client)
 	client.MobileDataSwitchState("1")
 	client.Connect()
 
@@ -732,12 +1050,12 @@ func handleDeleteApnSms(client *hilink.Client, message map[string]interface{}) {
 	parts := strings.Split(messageContent, ",")
 	partCount := len(parts)
 	if partCount == 2 {
-		profile := getProfileWithName(client, parts[1])
-		if profile == nil {
+		profile, err := client.ProfileByName(parts[1])
+		if err != nil {
 			sendSms(client, fmt.Sprintf("APN %s delete failed! Not found!", parts[1]), phoneNumber)
 			return
 		}
-		flag, err := deleteProfileWithIndex(client, profile["Index"].(string))
+		flag, err := deleteProfileWithIndex(client, profile.Index)
 
 		if err != nil {
 			// TODO check if profile with apn-name exists and retry
@@ -930,98 +1248,136 @@ func networkNotReachable() bool {
 	return true
 }
 
-func checkInitializedAndConnected() {
-	for true {
-		time.Sleep(NETWORK_CHECK_DELAY * time.Second)
-		client, err := getHilinkClient()
-		if err == nil {
-			deviceInfo, err := client.DeviceInfo()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				continue
+var errNetworkNotReachable = errors.New("network not reachable")
+
+func checkNetworkReachable() hilink.HealthCheck {
+	return hilink.HealthCheck{
+		Name: "ifconfig.io",
+		Check: func(ctx context.Context, c *hilink.Client) error {
+			if networkNotReachable() {
+				return errNetworkNotReachable
 			}
-			ipAddress := deviceInfo["WanIPAddress"].(string)
-			noNetworkAccess := networkNotReachable()
-			if ipAddress == "" && noNetworkAccess {
-				// statusInfo, err := client.StatusInfo()
-				// if err != nil {
-				// 	fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				// 	continue
-				// }
-
-				// initialize for new Huawei modem info
-
-				changed, err := checkAndInitProfile(client, deviceInfo)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "error: %v\n", err)
-					continue
-				}
-				if changed {
-					client.ConnectionProfile("1", "3600")
-					client.MobileDataSwitchState("1")
-					client.Connect()
-				}
+			return nil
+		},
+	}
+}
+
+// configureDialup enables roaming and automatic dialing, disconnecting after
+// an hour of inactivity.
+func configureDialup(client *hilink.Client) (bool, error) {
+	return client.DialupSettingsSet(func(s *hilink.DialupSettings) {
+		s.RoamingAutoConnect = true
+		s.MaxIdleTime = 3600
+		s.AutoDial = true
+	})
+}
 
-				client.PrivacyPolicy(true)
-				client.AutoUpdate(false)
-				client.OnlineUpdateConfig(false, false)
-				client.BasicDeviceInfo(true)
+// stepInitialize initializes a new Huawei modem: sets up the APN profile for
+// the SIM and accepts the privacy policy and update settings.
+func stepInitialize() hilink.RemediationStep {
+	return hilink.RemediationStep{
+		Name: "initialize",
+		Action: func(ctx context.Context, client *hilink.Client) error {
+			changed, err := checkAndInitProfile(client)
+			if err != nil {
+				return err
 			}
-		} else {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		}
+			if changed {
+				configureDialup(client)
+				client.MobileDataSwitchState("1")
+				client.Connect()
+			}
+
+			client.PrivacyPolicy(true)
+			client.AutoUpdate(false)
+			client.OnlineUpdateConfig(false, false)
+			client.BasicDeviceInfo(true)
+			return nil
+		},
+		Settle: NETWORK_CHECK_DELAY * time.Second,
 	}
 }
 
-func checkAndInitProfile(client *hilink.Client, deviceInfo map[string]interface{}) (bool, error) {
-	imsi := deviceInfo["Imsi"].(string)
-	imsiPrefix := imsi[0:5]
-	var newProfile ProfileRequest
-	switch imsiPrefix {
-	case "20408":
-		newProfile = ProfileRequest{
-			Name:      "simpoint.m2m",
-			ApnName:   "simpoint.m2m",
-			Username:  "",
-			Password:  "",
-			IsDefault: true,
-		}
+func logWatchdogEvent(ev hilink.WatchdogEvent) {
+	switch ev.Type {
+	case hilink.WatchdogRecovered:
+		fmt.Fprintf(os.Stdout, "watchdog: %v after %d failures\n", ev.Type, ev.Failures)
+	case hilink.WatchdogRemediation:
+		fmt.Fprintf(os.Stdout, "watchdog: %v %s\n", ev.Type, ev.Step)
 	default:
-		newProfile = ProfileRequest{
-			Name:      "",
-			ApnName:   "",
-			Username:  "",
-			Password:  "",
-			IsDefault: true,
-		}
+		fmt.Fprintf(os.Stderr, "watchdog: %v %s%s: %v\n", ev.Type, ev.Check, ev.Step, ev.Err)
 	}
-	if newProfile.Name != "" {
-		flag, err := createNewProfileFromRequest(client, newProfile)
-		if err != nil {
-			resetHilinkClient()
-			fmt.Fprintf(os.Stderr, "APN %s config failed! %v", newProfile.ApnName, err)
-			return false, err
-		}
-		return flag, nil
+}
+
+func checkInitializedAndConnected() {
+	steps := []hilink.RemediationStep{
+		stepInitialize(),
+		hilink.StepRedial(),
+		hilink.StepToggleDataSwitch(),
+		hilink.StepReregister(),
+	}
+	if *flagWatchdogReboot {
+		steps = append(steps, hilink.StepReboot())
+	}
+
+	// the client is recreated after errors, so retrieve it on each round
+	watchdog, err := hilink.NewWatchdog(nil,
+		hilink.WatchdogClientFunc(getHilinkClient),
+		hilink.WatchdogInterval(NETWORK_CHECK_DELAY*time.Second),
+		hilink.WatchdogChecks(
+			hilink.AnyCheck("network", hilink.CheckWanIP(), checkNetworkReachable()),
+		),
+		hilink.WatchdogSteps(steps...),
+		hilink.WatchdogEvents(logWatchdogEvent),
+	)
+	if err != nil {
+		log.Fatal(err)
 	}
-	fmt.Fprintf(os.Stderr, "APN %s config failed!", newProfile.ApnName)
-	return false, nil
+	watchdog.Run(context.Background())
+}
+
+func loadApnDatabase() (*hilink.ApnDatabase, error) {
+	db := hilink.DefaultApnDatabase()
+	if *flagApnDatabase == "" {
+		return db, nil
+	}
+	custom, err := hilink.LoadApnDatabaseFile(*flagApnDatabase)
+	if err != nil {
+		return nil, err
+	}
+	return db.Merge(custom), nil
+}
+
+func checkAndInitProfile(client *hilink.Client) (bool, error) {
+	res, err := client.Provision(apnDatabase)
+	if err != nil {
+		resetHilinkClient()
+		fmt.Fprintf(os.Stderr, "APN config failed! %v\n", err)
+		return false, err
+	}
+	fmt.Fprintf(os.Stdout, "APN provisioning: %v\n", res)
+	return res.Action == hilink.ProvisionCreated || res.Action == hilink.ProvisionSelected, nil
 }
 
 func rootLink(w http.ResponseWriter, r *http.Request) {
 	getJsonEncoder(w).Encode(map[string]string{
-		"/":                 "Hilink Proxy Root, list of available URIs",
-		"/device-info":      "General modem information",
-		"/connect":          "Connect to mobile network",
-		"/disconnect":       "Disconnect to mobile network",
-		"/connection-info":  "General modem connection settings",
-		"/current-profile":  "Connection profiles used by HiLink modem",
-		"/profile-info":     "Connection profiles used by HiLink modem",
-		"/profiles":         "Connection profiles available by HiLink modem",
-		"/profiles/{index}": "Connection profile, remove with method DELETE",
-		"/sms/inbox":        "List SMS from inbox",
-		"/sms/outbox":       "List SMS from outbox, send SMS using method POST",
-		"/sms/{index}":      "Delete SMS using method DELETE",
+		"/":                               "Hilink Proxy Root, list of available URIs",
+		"/device-info":                    "General modem information",
+		"/connect":                        "Connect to mobile network",
+		"/disconnect":                     "Disconnect to mobile network",
+		"/connection-info":                "General modem connection settings",
+		"/current-profile":                "Connection profiles used by HiLink modem",
+		"/hosts":                          "Hosts connected to the LAN/WiFi of the HiLink modem",
+		"/profile-info":                   "Connection profiles used by HiLink modem",
+		"/profiles":                       "Connection profiles available by HiLink modem",
+		"/profiles/{index}":               "Connection profile, update with method PUT, remove with method DELETE",
+		"/profiles/{index}/default":       "Set the default connection profile using method POST",
+		"/wlan/wps":                       "WPS state, start push-button mode and wait for a client using method POST",
+		"/security/port-forwards":         "Port forwarding rules, add rule using method POST",
+		"/security/port-forwards/{index}": "Port forwarding rule, update with method PUT, remove with method DELETE",
+		"/sms/inbox":                      "List SMS from inbox",
+		"/sms/outbox":                     "List SMS from outbox, send SMS using method POST",
+		"/sms/{index}":                    "Delete SMS using method DELETE",
 	})
 }
 
@@ -1035,6 +1391,14 @@ func rebootDevice() {
 }
 
 func main() {
+	flag.Parse()
+
+	var err error
+	apnDatabase, err = loadApnDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// initEvents()
 	go checkForSms()
 	go checkInitializedAndConnected()
@@ -1047,11 +1411,21 @@ func main() {
 	router.HandleFunc("/profiles", listProfiles).Methods("GET")
 	router.HandleFunc("/profiles", createProfile).Methods("POST")
 	router.HandleFunc("/profiles/{index}", getProfile).Methods("GET")
+	router.HandleFunc("/profiles/{index}", updateProfile).Methods("PUT")
+	router.HandleFunc("/profiles/{index}/default", setDefaultProfile).Methods("POST")
 	router.HandleFunc("/profiles/{index}", deleteProfile).Methods("DELETE")
 	router.HandleFunc("/connection-info", getConnectionInfo).Methods("GET")
 	router.HandleFunc("/connection-info", setConnectionInfo).Methods("PUT")
 	router.HandleFunc("/connect", connect).Methods("POST")
 	router.HandleFunc("/disconnect", disconnect).Methods("POST")
+	router.HandleFunc("/hosts", listHosts).Methods("GET")
+	router.HandleFunc("/wlan/wps", getWps).Methods("GET")
+	router.HandleFunc("/wlan/wps", startWps).Methods("POST")
+	router.HandleFunc("/security/port-forwards", listPortForwards).Methods("GET")
+	router.HandleFunc("/security/port-forwards", createPortForward).Methods("POST")
+	router.HandleFunc("/security/port-forwards/{index}", getPortForward).Methods("GET")
+	router.HandleFunc("/security/port-forwards/{index}", updatePortForward).Methods("PUT")
+	router.HandleFunc("/security/port-forwards/{index}", deletePortForward).Methods("DELETE")
 	router.HandleFunc("/sms/inbox", listSmsInbox).Methods("GET")
 	router.HandleFunc("/sms/outbox", listSmsOutbox).Methods("GET")
 	router.HandleFunc("/sms/outbox", sendNewSms).Methods("POST")
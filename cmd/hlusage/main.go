@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jpunie/hilink"
+	"github.com/jpunie/hilink/usage"
+)
+
+var (
+	flagEndpoint = flag.String("endpoint", "http://192.168.8.1/", "api endpoint")
+	flagDebug    = flag.Bool("v", false, "enable verbose")
+	flagDB       = flag.String("db", "hlusage.db", "usage database")
+	flagSample   = flag.Bool("sample", false, "sample traffic counters until interrupted")
+	flagInterval = flag.Duration("interval", usage.DefaultSampleInterval, "sample interval")
+	flagDays     = flag.Int("days", 14, "number of days in daily report")
+	flagMonths   = flag.Int("months", 6, "number of months in monthly report")
+)
+
+func main() {
+	flag.Parse()
+
+	store, err := usage.Open(*flagDB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	if *flagSample {
+		err = sample(store)
+	} else {
+		err = report(store)
+	}
+	if err != nil && err != context.Canceled {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// sample runs a sampler until interrupted.
+func sample(store *usage.Store) error {
+	// options
+	opts := []hilink.Option{
+		hilink.URL(*flagEndpoint),
+	}
+	if *flagDebug {
+		opts = append(opts, hilink.Log(log.Printf, log.Printf))
+	}
+
+	// create client
+	client, err := hilink.NewClient(opts...)
+	if err != nil {
+		return err
+	}
+
+	s, err := usage.NewSampler(client, store,
+		usage.SamplerInterval(*flagInterval),
+		usage.SamplerErrors(func(err error) {
+			log.Printf("error: %v", err)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	// stop on interrupt
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	return s.Run(ctx)
+}
+
+// report prints the daily and monthly usage tables.
+func report(store *usage.Store) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	days, err := store.Daily(today.AddDate(0, 0, 1-*flagDays), tomorrow)
+	if err != nil {
+		return err
+	}
+	printTable("DAY", "2006-01-02", days)
+
+	fmt.Fprintln(os.Stdout)
+
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	months, err := store.Monthly(month.AddDate(0, 1-*flagMonths, 0), tomorrow)
+	if err != nil {
+		return err
+	}
+	printTable("MONTH", "2006-01", months)
+
+	return nil
+}
+
+// printTable prints usage records as a table.
+func printTable(title, layout string, recs []usage.Record) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s\tUPLOAD\tDOWNLOAD\tTOTAL\t\n", title)
+
+	var total usage.Usage
+	for _, r := range recs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", r.Start.Format(layout), formatBytes(r.Upload), formatBytes(r.Download), formatBytes(r.Total()))
+		total.Upload += r.Upload
+		total.Download += r.Download
+	}
+	fmt.Fprintf(w, "total\t%s\t%s\t%s\t\n", formatBytes(total.Upload), formatBytes(total.Download), formatBytes(total.Total()))
+
+	w.Flush()
+}
+
+// formatBytes formats a byte count using binary units.
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,159 @@
+// Package config provides declarative configuration of Hilink devices: a
+// document describes the desired state of a device, which is compared with
+// the current state to produce a plan of the setter calls needed to reach
+// it.
+//
+// Settings missing from the document are left untouched on the device.
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jpunie/hilink"
+	"gopkg.in/yaml.v2"
+)
+
+// Document is the desired state of a device.
+type Document struct {
+	Language string    `json:"language,omitempty" yaml:"language,omitempty"`
+	Profiles []Profile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+	Dialup   *Dialup   `json:"dialup,omitempty" yaml:"dialup,omitempty"`
+	Network  *Network  `json:"network,omitempty" yaml:"network,omitempty"`
+	Security *Security `json:"security,omitempty" yaml:"security,omitempty"`
+	Wlan     []Wlan    `json:"wlan,omitempty" yaml:"wlan,omitempty"`
+}
+
+// Profile is a connection profile (APN), identified by its name. Profiles on
+// the device that are not in the document are left untouched.
+//
+// Username, Password, AuthMode and PDPType are left untouched when missing,
+// or are empty, none and ipv4 for created profiles. Default makes the profile
+// the default profile; at most one profile can be the default.
+type Profile struct {
+	Name     string           `json:"name" yaml:"name"`
+	ApnName  string           `json:"apn" yaml:"apn"`
+	Username *string          `json:"username,omitempty" yaml:"username,omitempty"`
+	Password *string          `json:"password,omitempty" yaml:"password,omitempty"`
+	AuthMode *hilink.AuthMode `json:"auth,omitempty" yaml:"auth,omitempty"`
+	PDPType  *hilink.PDPType  `json:"pdp,omitempty" yaml:"pdp,omitempty"`
+	Default  bool             `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// Dialup are the dialup connection settings.
+type Dialup struct {
+	ConnectMode        *hilink.ConnectMode `json:"connect_mode,omitempty" yaml:"connect_mode,omitempty"`
+	MTU                *uint               `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+	MaxIdleTime        *uint               `json:"max_idle_time,omitempty" yaml:"max_idle_time,omitempty"`
+	RoamingAutoConnect *bool               `json:"roaming_auto_connect,omitempty" yaml:"roaming_auto_connect,omitempty"`
+	AutoDial           *bool               `json:"auto_dial,omitempty" yaml:"auto_dial,omitempty"`
+	AlwaysOn           *bool               `json:"always_on,omitempty" yaml:"always_on,omitempty"`
+}
+
+// Network are the network mode settings, as used by ModeSet: Mode is the
+// network mode (eg, "00" for automatic, "03" for LTE only), and Band and
+// LTEBand are hexadecimal band masks.
+type Network struct {
+	Mode    string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Band    string `json:"band,omitempty" yaml:"band,omitempty"`
+	LTEBand string `json:"lte_band,omitempty" yaml:"lte_band,omitempty"`
+}
+
+// Security are the DMZ, NAT, UPnP and SIP ALG settings.
+type Security struct {
+	DMZ    *DMZ    `json:"dmz,omitempty" yaml:"dmz,omitempty"`
+	NAT    *uint   `json:"nat,omitempty" yaml:"nat,omitempty"`
+	UPnP   *bool   `json:"upnp,omitempty" yaml:"upnp,omitempty"`
+	SIPALG *SIPALG `json:"sip_alg,omitempty" yaml:"sip_alg,omitempty"`
+}
+
+// DMZ are the DMZ settings.
+type DMZ struct {
+	Enabled *bool   `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Host    *string `json:"host,omitempty" yaml:"host,omitempty"`
+}
+
+// SIPALG are the SIP application-level gateway settings.
+type SIPALG struct {
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Port    *uint `json:"port,omitempty" yaml:"port,omitempty"`
+}
+
+// Wlan are the settings of the WiFi network (SSID) with the specified index.
+type Wlan struct {
+	Index      uint                 `json:"index" yaml:"index"`
+	Enabled    *bool                `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	SSID       *string              `json:"ssid,omitempty" yaml:"ssid,omitempty"`
+	Hidden     *bool                `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	Channel    *int                 `json:"channel,omitempty" yaml:"channel,omitempty"`
+	MaxClients *int                 `json:"max_clients,omitempty" yaml:"max_clients,omitempty"`
+	AuthMode   *hilink.WlanAuthMode `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Encryption *string              `json:"encryption,omitempty" yaml:"encryption,omitempty"`
+	Key        *string              `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// Load reads a document in YAML or JSON format.
+func Load(r io.Reader) (*Document, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so this handles both
+	doc := new(Document)
+	if err = yaml.UnmarshalStrict(buf, doc); err != nil {
+		return nil, err
+	}
+
+	if err = doc.validate(); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// LoadFile reads a document from a YAML or JSON file.
+func LoadFile(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// validate checks the document is consistent. The settings themselves are
+// validated by the setters.
+func (doc *Document) validate() error {
+	names, defaults := make(map[string]bool), 0
+	for i, p := range doc.Profiles {
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("profile %d: no name", i)
+		case p.ApnName == "":
+			return fmt.Errorf("profile %q: no apn", p.Name)
+		case names[p.Name]:
+			return fmt.Errorf("profile %q: duplicate name", p.Name)
+		}
+		names[p.Name] = true
+		if p.Default {
+			defaults++
+		}
+	}
+	if defaults > 1 {
+		return fmt.Errorf("more than one default profile")
+	}
+
+	indexes := make(map[uint]bool)
+	for _, w := range doc.Wlan {
+		if indexes[w.Index] {
+			return fmt.Errorf("wlan %d: duplicate index", w.Index)
+		}
+		indexes[w.Index] = true
+	}
+
+	return nil
+}
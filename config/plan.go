@@ -0,0 +1,571 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jpunie/hilink"
+)
+
+// Change is the change of a single setting. From and To are masked for
+// passwords and keys.
+type Change struct {
+	Setting string
+	From    string
+	To      string
+}
+
+// String satisfies the fmt.Stringer interface.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.Setting, c.From, c.To)
+}
+
+// Step is a group of changes made with a single setter, along with the setter
+// call restoring the previous state.
+type Step struct {
+	Name    string
+	Changes []Change
+
+	apply  func() error
+	revert func() error
+}
+
+// Plan is the list of steps bringing a device to the state described by a
+// document.
+type Plan struct {
+	Steps []*Step
+}
+
+// Empty determines if the device already is in the desired state.
+func (p *Plan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// String satisfies the fmt.Stringer interface.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "no changes\n"
+	}
+
+	var s string
+	for _, st := range p.Steps {
+		s += st.Name + ":\n"
+		for _, c := range st.Changes {
+			s += "  ~ " + c.String() + "\n"
+		}
+	}
+	return s
+}
+
+// ApplyError is the error returned by Apply when a step fails. Rollback holds
+// the errors encountered while restoring the previous state.
+type ApplyError struct {
+	Step     string
+	Err      error
+	Rollback []error
+}
+
+// Error satisfies the error interface.
+func (e *ApplyError) Error() string {
+	if len(e.Rollback) != 0 {
+		return fmt.Sprintf("%s: %v (rollback failed: %v)", e.Step, e.Err, e.Rollback[0])
+	}
+	return fmt.Sprintf("%s: %v (rolled back)", e.Step, e.Err)
+}
+
+// Apply applies the steps of the plan in order. When a step fails, the steps
+// applied before it are reverted in reverse order, and an *ApplyError is
+// returned.
+func (p *Plan) Apply() error {
+	for i, st := range p.Steps {
+		err := st.apply()
+		if err == nil {
+			continue
+		}
+
+		e := &ApplyError{Step: st.Name, Err: err}
+		for j := i - 1; j >= 0; j-- {
+			if err = p.Steps[j].revert(); err != nil {
+				e.Rollback = append(e.Rollback, fmt.Errorf("%s: %v", p.Steps[j].Name, err))
+			}
+		}
+		return e
+	}
+	return nil
+}
+
+// planner builds a plan.
+type planner struct {
+	client *hilink.Client
+	plan   *Plan
+}
+
+// NewPlan retrieves the current state of the device using the client, and
+// builds the plan bringing it to the state described by doc. Only the
+// settings present in doc are retrieved.
+//
+// The WiFi settings are applied last, as the device restarts the WiFi,
+// possibly dropping the connection used by the client.
+func NewPlan(client *hilink.Client, doc *Document) (*Plan, error) {
+	p := &planner{client: client, plan: new(Plan)}
+	for _, f := range []func(*Document) error{
+		p.language,
+		p.profiles,
+		p.dialup,
+		p.network,
+		p.security,
+		p.wlan,
+	} {
+		if err := f(doc); err != nil {
+			return nil, err
+		}
+	}
+	return p.plan, nil
+}
+
+// add adds a step to the plan, when there are changes.
+func (p *planner) add(name string, changes []Change, apply, revert func() error) {
+	if len(changes) == 0 {
+		return
+	}
+	p.plan.Steps = append(p.plan.Steps, &Step{
+		Name:    name,
+		Changes: changes,
+		apply:   apply,
+		revert:  revert,
+	})
+}
+
+// diff is a helper collecting the changes of a step.
+type diff struct {
+	prefix  string
+	changes []Change
+}
+
+// add adds a change when from and to differ.
+func (d *diff) add(setting string, from, to interface{}) {
+	f, t := fmt.Sprintf("%v", from), fmt.Sprintf("%v", to)
+	if f != t {
+		d.changes = append(d.changes, Change{Setting: d.prefix + setting, From: f, To: t})
+	}
+}
+
+// addSecret adds a change when from and to differ, masking the values. A
+// masked value returned by the device (from) is considered unchanged, as the
+// actual value is unknown.
+func (d *diff) addSecret(setting, from, to string) {
+	if from != to && !hilink.IsMasked(from) {
+		d.changes = append(d.changes, Change{Setting: d.prefix + setting, From: mask(from), To: mask(to)})
+	}
+}
+
+// mask masks a secret value.
+func mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "********"
+}
+
+// language plans the language change.
+func (p *planner) language(doc *Document) error {
+	if doc.Language == "" {
+		return nil
+	}
+
+	cur, err := p.client.Language()
+	if err != nil {
+		return err
+	}
+
+	d := diff{}
+	d.add("language", cur, doc.Language)
+	p.add("language", d.changes, func() error {
+		return hilink.CheckOK(p.client.LanguageSet(doc.Language))
+	}, func() error {
+		return hilink.CheckOK(p.client.LanguageSet(cur))
+	})
+	return nil
+}
+
+// profileWith returns p with the settings of want present in the document.
+func profileWith(p hilink.Profile, want Profile) hilink.Profile {
+	p.Name, p.ApnName = want.Name, want.ApnName
+	if v := want.Username; v != nil {
+		p.Username = *v
+	}
+	if v := want.Password; v != nil {
+		p.Password = *v
+	}
+	if v := want.AuthMode; v != nil {
+		p.AuthMode = *v
+	}
+	if v := want.PDPType; v != nil {
+		p.PDPType = *v
+	}
+	return p
+}
+
+// profileDiff collects the changes from profile cur to want.
+func profileDiff(prefix string, cur, want hilink.Profile) []Change {
+	d := diff{prefix: prefix}
+	d.add("apn", cur.ApnName, want.ApnName)
+	d.add("username", cur.Username, want.Username)
+	d.addSecret("password", cur.Password, want.Password)
+	d.add("auth", cur.AuthMode, want.AuthMode)
+	d.add("pdp", cur.PDPType, want.PDPType)
+	return d.changes
+}
+
+// profiles plans the connection profile changes. Profiles are created or
+// updated first, after which the default profile is changed.
+func (p *planner) profiles(doc *Document) error {
+	if len(doc.Profiles) == 0 {
+		return nil
+	}
+
+	profiles, err := p.client.ProfileList()
+	if err != nil {
+		return err
+	}
+	var current hilink.Profile
+	existing := make(map[string]hilink.Profile)
+	for _, e := range profiles {
+		existing[e.Name] = e
+		if e.IsDefault {
+			current = e
+		}
+	}
+
+	var def string
+	for _, want := range doc.Profiles {
+		want := want
+		if want.Default {
+			def = want.Name
+		}
+		name := fmt.Sprintf("profile %q", want.Name)
+		prefix := "profiles." + want.Name + "."
+
+		cur, ok := existing[want.Name]
+		if !ok {
+			// create
+			created := profileWith(hilink.Profile{}, want)
+			p.add(name, profileDiff(prefix, hilink.Profile{}, created), func() error {
+				return hilink.CheckOK(p.client.ProfileCreate(created))
+			}, func() error {
+				e, err := p.client.ProfileByName(want.Name)
+				if err != nil {
+					return err
+				}
+				def, err := p.fallbackDefault(current, e.Index)
+				if err != nil {
+					return err
+				}
+				return hilink.CheckOK(p.client.ProfileRemove(e.Index, def))
+			})
+			continue
+		}
+
+		// update, keeping the settings not managed by the document; masked
+		// passwords are left unchanged by ProfileUpdate
+		upd := profileWith(cur, want)
+		changes := profileDiff(prefix, cur, upd)
+		if len(changes) != 0 && cur.ReadOnly {
+			return fmt.Errorf("%s: %v", name, hilink.ErrProfileReadOnly)
+		}
+		upd.IsDefault, cur.IsDefault = false, false
+		p.add(name, changes, func() error {
+			return hilink.CheckOK(p.client.ProfileUpdate(upd.Index, upd))
+		}, func() error {
+			return hilink.CheckOK(p.client.ProfileUpdate(cur.Index, cur))
+		})
+	}
+
+	if def == "" || def == current.Name {
+		return nil
+	}
+	d := diff{}
+	d.add("profiles.default", current.Name, def)
+	p.add("default profile", d.changes, func() error {
+		// the profile may have just been created
+		e, err := p.client.ProfileByName(def)
+		if err != nil {
+			return err
+		}
+		return hilink.CheckOK(p.client.ProfileSetDefault(e.Index))
+	}, func() error {
+		// there is no way to go back to having no default profile
+		if current.Index == 0 {
+			return nil
+		}
+		return hilink.CheckOK(p.client.ProfileSetDefault(current.Index))
+	})
+	return nil
+}
+
+// fallbackDefault returns the default profile to set when deleting the
+// profile with index: the previous default profile, or when there was none,
+// the first remaining profile (0 when there is none left).
+func (p *planner) fallbackDefault(current hilink.Profile, index uint) (uint, error) {
+	if current.Index != 0 && current.Index != index {
+		return current.Index, nil
+	}
+
+	profiles, err := p.client.ProfileList()
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range profiles {
+		if e.Index != index {
+			return e.Index, nil
+		}
+	}
+	return 0, nil
+}
+
+// dialup plans the dialup settings changes.
+func (p *planner) dialup(doc *Document) error {
+	if doc.Dialup == nil {
+		return nil
+	}
+
+	cur, err := p.client.DialupSettings()
+	if err != nil {
+		return err
+	}
+	old, want := *cur, *cur
+	if v := doc.Dialup.ConnectMode; v != nil {
+		want.ConnectMode = *v
+	}
+	if v := doc.Dialup.MTU; v != nil {
+		want.MTU = *v
+	}
+	if v := doc.Dialup.MaxIdleTime; v != nil {
+		want.MaxIdleTime = *v
+	}
+	if v := doc.Dialup.RoamingAutoConnect; v != nil {
+		want.RoamingAutoConnect = *v
+	}
+	if v := doc.Dialup.AutoDial; v != nil {
+		want.AutoDial = *v
+	}
+	if v := doc.Dialup.AlwaysOn; v != nil {
+		want.AlwaysOn = *v
+	}
+
+	d := diff{prefix: "dialup."}
+	d.add("connect_mode", old.ConnectMode, want.ConnectMode)
+	d.add("mtu", old.MTU, want.MTU)
+	d.add("max_idle_time", old.MaxIdleTime, want.MaxIdleTime)
+	d.add("roaming_auto_connect", old.RoamingAutoConnect, want.RoamingAutoConnect)
+	d.add("auto_dial", old.AutoDial, want.AutoDial)
+	d.add("always_on", old.AlwaysOn, want.AlwaysOn)
+	p.add("dialup", d.changes, func() error {
+		return hilink.CheckOK(p.client.DialupSettingsSet(func(s *hilink.DialupSettings) {
+			*s = want
+		}))
+	}, func() error {
+		return hilink.CheckOK(p.client.DialupSettingsSet(func(s *hilink.DialupSettings) {
+			*s = old
+		}))
+	})
+	return nil
+}
+
+// network plans the network mode changes.
+func (p *planner) network(doc *Document) error {
+	if doc.Network == nil {
+		return nil
+	}
+
+	d, err := p.client.ModeInfo()
+	if err != nil {
+		return err
+	}
+	mode, band, lteBand := hilink.XMLString(d, "NetworkMode"), hilink.XMLString(d, "NetworkBand"), hilink.XMLString(d, "LTEBand")
+
+	// band masks are hexadecimal, compare them case-insensitively
+	wantMode, wantBand, wantLTEBand := mode, band, lteBand
+	if v := doc.Network.Mode; v != "" {
+		wantMode = v
+	}
+	if v := doc.Network.Band; v != "" && !strings.EqualFold(v, band) {
+		wantBand = v
+	}
+	if v := doc.Network.LTEBand; v != "" && !strings.EqualFold(v, lteBand) {
+		wantLTEBand = v
+	}
+
+	c := diff{prefix: "network."}
+	c.add("mode", mode, wantMode)
+	c.add("band", band, wantBand)
+	c.add("lte_band", lteBand, wantLTEBand)
+	p.add("network mode", c.changes, func() error {
+		return hilink.CheckOK(p.client.ModeSet(wantMode, wantBand, wantLTEBand))
+	}, func() error {
+		return hilink.CheckOK(p.client.ModeSet(mode, band, lteBand))
+	})
+	return nil
+}
+
+// security plans the DMZ, NAT, UPnP and SIP ALG changes.
+func (p *planner) security(doc *Document) error {
+	s := doc.Security
+	if s == nil {
+		return nil
+	}
+
+	if s.DMZ != nil {
+		d, err := p.client.DmzConfig()
+		if err != nil {
+			return err
+		}
+		enabled, host := hilink.XMLString(d, "DmzStatus") == "1", hilink.XMLString(d, "DmzIPAddress")
+		wantEnabled, wantHost := enabled, host
+		if v := s.DMZ.Enabled; v != nil {
+			wantEnabled = *v
+		}
+		if v := s.DMZ.Host; v != nil {
+			wantHost = *v
+		}
+
+		c := diff{prefix: "security.dmz."}
+		c.add("enabled", enabled, wantEnabled)
+		c.add("host", host, wantHost)
+		p.add("dmz", c.changes, func() error {
+			return hilink.CheckOK(p.client.DmzConfigSet(wantEnabled, wantHost))
+		}, func() error {
+			return hilink.CheckOK(p.client.DmzConfigSet(enabled, host))
+		})
+	}
+
+	if s.NAT != nil {
+		d, err := p.client.NatType()
+		if err != nil {
+			return err
+		}
+		ntype, _ := strconv.ParseUint(hilink.XMLString(d, "NATType"), 10, 32)
+		cur, want := uint(ntype), *s.NAT
+
+		c := diff{prefix: "security."}
+		c.add("nat", cur, want)
+		p.add("nat", c.changes, func() error {
+			return hilink.CheckOK(p.client.NatTypeSet(want))
+		}, func() error {
+			return hilink.CheckOK(p.client.NatTypeSet(cur))
+		})
+	}
+
+	if s.UPnP != nil {
+		d, err := p.client.Upnp()
+		if err != nil {
+			return err
+		}
+		cur, want := hilink.XMLString(d, "UpnpStatus") == "1", *s.UPnP
+
+		c := diff{prefix: "security."}
+		c.add("upnp", cur, want)
+		p.add("upnp", c.changes, func() error {
+			return hilink.CheckOK(p.client.UpnpSet(want))
+		}, func() error {
+			return hilink.CheckOK(p.client.UpnpSet(cur))
+		})
+	}
+
+	if s.SIPALG != nil {
+		d, err := p.client.SipAlg()
+		if err != nil {
+			return err
+		}
+		port64, _ := strconv.ParseUint(hilink.XMLString(d, "SipPort"), 10, 32)
+		enabled, port := hilink.XMLString(d, "SipStatus") == "1", uint(port64)
+		wantEnabled, wantPort := enabled, port
+		if v := s.SIPALG.Enabled; v != nil {
+			wantEnabled = *v
+		}
+		if v := s.SIPALG.Port; v != nil {
+			wantPort = *v
+		}
+
+		c := diff{prefix: "security.sip_alg."}
+		c.add("enabled", enabled, wantEnabled)
+		c.add("port", port, wantPort)
+		p.add("sip alg", c.changes, func() error {
+			return hilink.CheckOK(p.client.SipAlgSet(wantPort, wantEnabled))
+		}, func() error {
+			return hilink.CheckOK(p.client.SipAlgSet(port, enabled))
+		})
+	}
+
+	return nil
+}
+
+// wlan plans the WiFi settings changes.
+func (p *planner) wlan(doc *Document) error {
+	if len(doc.Wlan) == 0 {
+		return nil
+	}
+
+	settings, err := p.client.WlanSettings()
+	if err != nil {
+		return err
+	}
+	existing := make(map[uint]hilink.WlanSettings)
+	for _, s := range settings {
+		existing[s.Index] = s
+	}
+
+	for _, w := range doc.Wlan {
+		old, ok := existing[w.Index]
+		if !ok {
+			return fmt.Errorf("wlan %d: %v", w.Index, hilink.ErrSsidNotFound)
+		}
+		want := old
+		if v := w.Enabled; v != nil {
+			want.Enabled = *v
+		}
+		if v := w.SSID; v != nil {
+			want.SSID = *v
+		}
+		if v := w.Hidden; v != nil {
+			want.Hidden = *v
+		}
+		if v := w.Channel; v != nil {
+			want.Channel = *v
+		}
+		if v := w.MaxClients; v != nil {
+			want.MaxClients = *v
+		}
+		if v := w.AuthMode; v != nil {
+			want.AuthMode = *v
+		}
+		if v := w.Encryption; v != nil {
+			want.Encryption = *v
+		}
+		if v := w.Key; v != nil {
+			want.Key = *v
+		}
+
+		c := diff{prefix: fmt.Sprintf("wlan.%d.", w.Index)}
+		c.add("enabled", old.Enabled, want.Enabled)
+		c.add("ssid", old.SSID, want.SSID)
+		c.add("hidden", old.Hidden, want.Hidden)
+		c.add("channel", old.Channel, want.Channel)
+		c.add("max_clients", old.MaxClients, want.MaxClients)
+		c.add("auth", old.AuthMode, want.AuthMode)
+		c.add("encryption", old.Encryption, want.Encryption)
+		c.addSecret("key", old.Key, want.Key)
+		p.add(fmt.Sprintf("wlan %d", w.Index), c.changes, func() error {
+			return hilink.CheckOK(p.client.WlanSettingsSet(want.Index, func(s *hilink.WlanSettings) {
+				*s = want
+			}))
+		}, func() error {
+			return hilink.CheckOK(p.client.WlanSettingsSet(old.Index, func(s *hilink.WlanSettings) {
+				*s = old
+			}))
+		})
+	}
+
+	return nil
+}
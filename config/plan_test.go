@@ -0,0 +1,357 @@
+package config
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jpunie/hilink"
+)
+
+// testProfile is a connection profile of the fake device.
+type testProfile struct {
+	Index    int
+	Name     string
+	ApnName  string
+	Username string
+	Password string
+}
+
+// testDevice is a fake device serving the language, UPnP and connection
+// profile APIs.
+type testDevice struct {
+	language string
+	upnp     string
+	current  int
+	profiles []testProfile
+
+	// failUpnp makes the UPnP setter fail.
+	failUpnp bool
+
+	sync.Mutex
+}
+
+// profileRequest is a connection profile modification request.
+type profileRequest struct {
+	Delete     int
+	SetDefault int
+	Modify     int
+	Profile    testProfile
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (d *testDevice) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.Lock()
+	defer d.Unlock()
+
+	buf, _ := ioutil.ReadAll(req.Body)
+	body := string(buf)
+	post := req.Method == "POST"
+
+	var res string
+	switch req.URL.Path {
+	case "/api/language/current-language":
+		if post {
+			d.language = between(body, "<CurrentLanguage>", "</CurrentLanguage>")
+			res = "OK"
+		} else {
+			res = "<CurrentLanguage>" + d.language + "</CurrentLanguage>"
+		}
+
+	case "/api/security/upnp":
+		switch {
+		case post && d.failUpnp:
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><error><code>100003</code><message></message></error>`)
+			return
+		case post:
+			d.upnp = between(body, "<UpnpStatus>", "</UpnpStatus>")
+			res = "OK"
+		default:
+			res = "<UpnpStatus>" + d.upnp + "</UpnpStatus>"
+		}
+
+	case "/api/dialup/profiles":
+		if post {
+			var r profileRequest
+			if err := xml.Unmarshal(buf, &r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			d.modifyProfiles(r, strings.Contains(body, "<Password>"))
+			res = "OK"
+		} else {
+			res = fmt.Sprintf("<CurrentProfile>%d</CurrentProfile><Profiles>", d.current)
+			for _, p := range d.profiles {
+				res += fmt.Sprintf("<Profile><Index>%d</Index><IsValid>1</IsValid><Name>%s</Name><ApnName>%s</ApnName><Username>%s</Username><Password>%s</Password><ReadOnly>0</ReadOnly><iptype>0</iptype></Profile>", p.Index, p.Name, p.ApnName, p.Username, p.Password)
+			}
+			res += "</Profiles>"
+		}
+
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><response>%s</response>`, res)
+}
+
+// modifyProfiles applies a connection profile modification request. The
+// password of an updated profile is kept when the request has none.
+func (d *testDevice) modifyProfiles(r profileRequest, password bool) {
+	switch {
+	case r.Delete != 0:
+		for i, p := range d.profiles {
+			if p.Index == r.Delete {
+				d.profiles = append(d.profiles[:i], d.profiles[i+1:]...)
+				break
+			}
+		}
+	case r.Modify == 1:
+		index := 1
+		for _, p := range d.profiles {
+			if p.Index >= index {
+				index = p.Index + 1
+			}
+		}
+		r.Profile.Index = index
+		d.profiles = append(d.profiles, r.Profile)
+	case r.Modify == 2:
+		for i, p := range d.profiles {
+			if p.Index == r.Profile.Index {
+				if !password {
+					r.Profile.Password = p.Password
+				}
+				d.profiles[i] = r.Profile
+			}
+		}
+	}
+	d.current = r.SetDefault
+}
+
+// between returns the part of s between start and end.
+func between(s, start, end string) string {
+	i := strings.Index(s, start)
+	if i < 0 {
+		return ""
+	}
+	s = s[i+len(start):]
+	if j := strings.Index(s, end); j >= 0 {
+		return s[:j]
+	}
+	return s
+}
+
+// newTestClient creates a client for the fake device.
+func newTestClient(t *testing.T, d *testDevice) (*hilink.Client, func()) {
+	s := httptest.NewServer(d)
+	client, err := hilink.NewClient(hilink.URL(s.URL), hilink.NoSessionStart)
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	return client, s.Close
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to interface{}
+		secret   bool
+		exp      []Change
+	}{
+		{"unchanged", "en-us", "en-us", false, nil},
+		{"changed", "en-us", "de-de", false, []Change{{"x.s", "en-us", "de-de"}}},
+		{"bool", false, true, false, []Change{{"x.s", "false", "true"}}},
+		{"typed", hilink.AuthModeNone, hilink.AuthModePAP, false, []Change{{"x.s", "none", "pap"}}},
+		{"secret unchanged", "pw", "pw", true, nil},
+		{"secret changed", "", "pw", true, []Change{{"x.s", "", "********"}}},
+		{"secret removed", "pw", "", true, []Change{{"x.s", "********", ""}}},
+		{"secret masked", "****", "pw", true, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := diff{prefix: "x."}
+			if test.secret {
+				d.addSecret("s", test.from.(string), test.to.(string))
+			} else {
+				d.add("s", test.from, test.to)
+			}
+			if !reflect.DeepEqual(d.changes, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, d.changes)
+			}
+		})
+	}
+}
+
+// str returns a pointer to s.
+func str(s string) *string {
+	return &s
+}
+
+func TestNewPlan(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name  string
+		doc   *Document
+		steps []string
+		exp   string
+	}{
+		{"empty", &Document{}, nil, "no changes\n"},
+		{"unchanged", &Document{
+			Language: "en-us",
+			Profiles: []Profile{{Name: "work", ApnName: "internet", Default: true}},
+		}, nil, "no changes\n"},
+		{"changes", &Document{
+			Language: "de-de",
+			Profiles: []Profile{
+				{Name: "work", ApnName: "internet.work"},
+				{Name: "home", ApnName: "internet.home", Password: str("secret"), Default: true},
+			},
+			Security: &Security{UPnP: &enabled},
+		}, []string{`profile "work"`, `profile "home"`, "default profile", "upnp"}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &testDevice{
+				language: "en-us",
+				upnp:     "0",
+				current:  1,
+				profiles: []testProfile{{1, "work", "internet", "", ""}},
+			}
+			client, done := newTestClient(t, d)
+			defer done()
+
+			p, err := NewPlan(client, test.doc)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if test.exp != "" && p.String() != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, p.String())
+			}
+			var steps []string
+			for _, st := range p.Steps {
+				steps = append(steps, st.Name)
+			}
+			if test.steps != nil && !reflect.DeepEqual(steps, append([]string{"language"}, test.steps...)) {
+				t.Errorf("expected steps %v, got: %v", test.steps, steps)
+			}
+			if strings.Contains(p.String(), "secret") {
+				t.Errorf("expected password to be masked, got: %s", p.String())
+			}
+		})
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	enabled := true
+	doc := &Document{
+		Language: "de-de",
+		Profiles: []Profile{{Name: "home", ApnName: "internet.home", Default: true}},
+		Security: &Security{UPnP: &enabled},
+	}
+
+	tests := []struct {
+		name     string
+		current  int
+		failUpnp bool
+		language string
+		profiles []testProfile
+		def      int
+	}{
+		{"applied", 1, false, "de-de", []testProfile{{1, "work", "internet", "", ""}, {2, "home", "internet.home", "", ""}}, 2},
+		{"rolled back", 1, true, "en-us", []testProfile{{1, "work", "internet", "", ""}}, 1},
+		{"rolled back without default", 0, true, "en-us", []testProfile{{1, "work", "internet", "", ""}}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &testDevice{
+				language: "en-us",
+				upnp:     "0",
+				current:  test.current,
+				profiles: []testProfile{{1, "work", "internet", "", ""}},
+				failUpnp: test.failUpnp,
+			}
+			client, done := newTestClient(t, d)
+			defer done()
+
+			p, err := NewPlan(client, doc)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			err = p.Apply()
+			if test.failUpnp {
+				e, ok := err.(*ApplyError)
+				if !ok || e.Step != "upnp" || len(e.Rollback) != 0 {
+					t.Errorf("expected upnp step to fail and be rolled back, got: %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+
+			if d.language != test.language {
+				t.Errorf("expected language %s, got: %s", test.language, d.language)
+			}
+			if !reflect.DeepEqual(d.profiles, test.profiles) {
+				t.Errorf("expected profiles %v, got: %v", test.profiles, d.profiles)
+			}
+			if d.current != test.def {
+				t.Errorf("expected default profile %d, got: %d", test.def, d.current)
+			}
+		})
+	}
+}
+
+func TestPlanProfileUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		device  testProfile
+		want    Profile
+		changes []string
+		exp     testProfile
+	}{
+		{"masked password unchanged", testProfile{1, "work", "internet", "user", "****"},
+			Profile{Name: "work", ApnName: "internet", Password: str("secret")},
+			nil, testProfile{1, "work", "internet", "user", "****"}},
+		{"masked password kept", testProfile{1, "work", "internet", "user", "****"},
+			Profile{Name: "work", ApnName: "internet.new"},
+			[]string{"profiles.work.apn"}, testProfile{1, "work", "internet.new", "user", "****"}},
+		{"missing settings kept", testProfile{1, "work", "internet", "user", "pw"},
+			Profile{Name: "work", ApnName: "internet.new"},
+			[]string{"profiles.work.apn"}, testProfile{1, "work", "internet.new", "user", "pw"}},
+		{"settings changed", testProfile{1, "work", "internet", "user", "pw"},
+			Profile{Name: "work", ApnName: "internet", Username: str(""), Password: str("secret")},
+			[]string{"profiles.work.username", "profiles.work.password"}, testProfile{1, "work", "internet", "", "secret"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &testDevice{current: 1, profiles: []testProfile{test.device}}
+			client, done := newTestClient(t, d)
+			defer done()
+
+			p, err := NewPlan(client, &Document{Profiles: []Profile{test.want}})
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			var changes []string
+			for _, st := range p.Steps {
+				for _, c := range st.Changes {
+					changes = append(changes, c.Setting)
+				}
+			}
+			if !reflect.DeepEqual(changes, test.changes) {
+				t.Errorf("expected changes %v, got: %v", test.changes, changes)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(d.profiles, []testProfile{test.exp}) {
+				t.Errorf("expected profile %v, got: %v", test.exp, d.profiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package hilink
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDdnsNotFound is the DDNS entry not found error.
+var ErrDdnsNotFound = errors.New("ddns entry not found")
+
+// DdnsEntry is a DDNS (dynamic DNS) entry, keeping Hostname in Domain pointed
+// at the WAN IP address of the device.
+//
+// UpdateStatus is the result of the last update, as reported by the firmware.
+type DdnsEntry struct {
+	Index        uint
+	Provider     string
+	Hostname     string
+	Domain       string
+	Username     string
+	Password     string
+	Enabled      bool
+	UpdateStatus string
+}
+
+// DdnsEntries retrieves the DDNS entries.
+func (c *Client) DdnsEntries() ([]DdnsEntry, error) {
+	d, err := c.DdnsList()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []DdnsEntry{}
+	if l, ok := d["ddnss"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["ddns"]) {
+			entries = append(entries, DdnsEntry{
+				Index:        uint(xmlInt(m, "index")),
+				Provider:     xmlString(m, "provider"),
+				Hostname:     xmlString(m, "hostname"),
+				Domain:       xmlString(m, "domainname"),
+				Username:     xmlString(m, "username"),
+				Password:     xmlString(m, "password"),
+				Enabled:      xmlString(m, "status") == "1",
+				UpdateStatus: xmlString(m, "ddnsstatus"),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// ddnsEntry retrieves the DDNS entry with the specified index.
+func (c *Client) ddnsEntry(index uint) (*DdnsEntry, error) {
+	entries, err := c.DdnsEntries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Index == index {
+			return &e, nil
+		}
+	}
+	return nil, ErrDdnsNotFound
+}
+
+// doReqDdns wraps a DDNS entry modification request (operate values:
+// 1-create, 2-update, 3-delete). The request is encrypted when required by
+// the device, as it contains the provider password.
+func (c *Client) doReqDdns(operate int, e DdnsEntry) (bool, error) {
+	if operate != 3 && (e.Provider == "" || e.Hostname == "") {
+		return false, ErrInvalidValue
+	}
+
+	// note: the order is important!
+	vals := []string{
+		"index", fmt.Sprintf("%d", e.Index),
+		"provider", xmlEscape(e.Provider),
+		"username", xmlEscape(e.Username),
+	}
+
+	// masked passwords, as returned by some firmwares, are left unchanged
+	if !IsMasked(e.Password) {
+		vals = append(vals, "password", xmlEscape(e.Password))
+	}
+
+	return c.doReqCheckOKEncrypted("api/ddns/set-ddns", SimpleRequestXML(
+		"ddnss", "\n    <ddns>\n"+xmlPairsString("      ", append(vals,
+			"hostname", xmlEscape(e.Hostname),
+			"domainname", xmlEscape(e.Domain),
+			"status", boolToString(e.Enabled),
+			"operate", fmt.Sprintf("%d", operate),
+		)...)+"    </ddns>\n  ",
+	))
+}
+
+// DdnsCreate creates a DDNS entry. The entry index is assigned by the device.
+func (c *Client) DdnsCreate(e DdnsEntry) (bool, error) {
+	e.Index = 0
+	return c.doReqDdns(1, e)
+}
+
+// DdnsUpdate modifies the DDNS entry with the specified index. A masked
+// password, as returned by DdnsEntries on some firmwares, is left unchanged.
+func (c *Client) DdnsUpdate(index uint, e DdnsEntry) (bool, error) {
+	if _, err := c.ddnsEntry(index); err != nil {
+		return false, err
+	}
+	e.Index = index
+	return c.doReqDdns(2, e)
+}
+
+// DdnsDelete deletes the DDNS entry with the specified index.
+func (c *Client) DdnsDelete(index uint) (bool, error) {
+	e, err := c.ddnsEntry(index)
+	if err != nil {
+		return false, err
+	}
+	return c.doReqDdns(3, *e)
+}
@@ -0,0 +1,335 @@
+package hilink
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidIPAddress is the invalid IP address error.
+	ErrInvalidIPAddress = errors.New("invalid IP address")
+
+	// ErrStaticLeaseNotFound is the static lease not found error.
+	ErrStaticLeaseNotFound = errors.New("static lease not found")
+)
+
+// DNSMode is the DNS mode of the DHCP server.
+type DNSMode int
+
+// DNSMode values.
+const (
+	DNSModeManual DNSMode = iota
+	DNSModeRelay
+)
+
+// dnsModeNames are the names for the DNSMode values.
+var dnsModeNames = []string{"manual", "relay"}
+
+// String satisfies the fmt.Stringer interface.
+func (m DNSMode) String() string {
+	if m >= 0 && int(m) < len(dnsModeNames) {
+		return dnsModeNames[m]
+	}
+	return fmt.Sprintf("unknown (%d)", int(m))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (m DNSMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (m *DNSMode) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for i, n := range dnsModeNames {
+		if s == n || s == strconv.Itoa(i) {
+			*m = DNSMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid dns mode %q", s)
+}
+
+// DhcpSettings are the LAN and DHCP server settings.
+//
+// With DNSModeRelay the device hands out its own address as DNS server,
+// otherwise PrimaryDNS and SecondaryDNS are handed out.
+type DhcpSettings struct {
+	IPAddress    string
+	Netmask      string
+	Enabled      bool
+	StartIP      string
+	EndIP        string
+	LeaseTime    time.Duration
+	DNSMode      DNSMode
+	PrimaryDNS   string
+	SecondaryDNS string
+}
+
+// parseIPv4 parses an IPv4 address.
+func parseIPv4(s string) (net.IP, error) {
+	ip := net.ParseIP(strings.TrimSpace(s)).To4()
+	if ip == nil {
+		return nil, ErrInvalidIPAddress
+	}
+	return ip, nil
+}
+
+// validate checks the settings are valid: the pool must be within the LAN
+// subnet, and must not contain the LAN IP address.
+func (s *DhcpSettings) validate() error {
+	ip, err := parseIPv4(s.IPAddress)
+	if err != nil {
+		return err
+	}
+	mask, err := parseIPv4(s.Netmask)
+	if err != nil {
+		return err
+	}
+	if ones, bits := net.IPMask(mask).Size(); ones == 0 && bits == 0 {
+		return ErrInvalidValue
+	}
+	subnet := &net.IPNet{IP: ip.Mask(net.IPMask(mask)), Mask: net.IPMask(mask)}
+
+	start, err := parseIPv4(s.StartIP)
+	if err != nil {
+		return err
+	}
+	end, err := parseIPv4(s.EndIP)
+	if err != nil {
+		return err
+	}
+	if !subnet.Contains(start) || !subnet.Contains(end) || bytes.Compare(start, end) > 0 {
+		return ErrInvalidValue
+	}
+	if bytes.Compare(start, ip) <= 0 && bytes.Compare(ip, end) <= 0 {
+		return ErrInvalidValue
+	}
+
+	if s.LeaseTime < time.Second {
+		return ErrInvalidValue
+	}
+
+	if s.DNSMode == DNSModeManual {
+		if _, err = parseIPv4(s.PrimaryDNS); err != nil {
+			return err
+		}
+		if s.SecondaryDNS != "" {
+			if _, err = parseIPv4(s.SecondaryDNS); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DhcpSettings retrieves the LAN and DHCP server settings.
+func (c *Client) DhcpSettings() (*DhcpSettings, error) {
+	d, err := c.DhcpConfig()
+	if err != nil {
+		return nil, err
+	}
+	return parseDhcpSettings(d), nil
+}
+
+// parseDhcpSettings converts the XMLData returned by DhcpConfig into
+// DhcpSettings.
+func parseDhcpSettings(d XMLData) *DhcpSettings {
+	return &DhcpSettings{
+		IPAddress:    xmlString(d, "DhcpIPAddress"),
+		Netmask:      xmlString(d, "DhcpLanNetmask"),
+		Enabled:      xmlString(d, "DhcpStatus") == "1",
+		StartIP:      xmlString(d, "DhcpStartIPAddress"),
+		EndIP:        xmlString(d, "DhcpEndIPAddress"),
+		LeaseTime:    xmlSeconds(d, "DhcpLeaseTime"),
+		DNSMode:      DNSMode(xmlInt(d, "DnsStatus")),
+		PrimaryDNS:   xmlString(d, "PrimaryDns"),
+		SecondaryDNS: xmlString(d, "SecondaryDns"),
+	}
+}
+
+// DhcpSettingsSet changes the LAN and DHCP server settings, preserving any
+// additional settings reported by the firmware.
+//
+// When the LAN IP address of the device changes and the client is using the
+// old address, the client URL is changed to the new address and the session
+// is re-established. As the device restarts its LAN, and the host may need a
+// new address in the new subnet, this can fail; use WaitForDevice to
+// re-establish the session once the device is reachable again.
+func (c *Client) DhcpSettingsSet(s DhcpSettings) (bool, error) {
+	if err := s.validate(); err != nil {
+		return false, err
+	}
+
+	d, err := c.DhcpConfig()
+	if err != nil {
+		return false, err
+	}
+	cur := parseDhcpSettings(d)
+
+	// note: the order is important!
+	ok, err := c.doReqCheckOK("api/dhcp/settings", SimpleRequestXML(xmlAppendExtra([]string{
+		"DhcpIPAddress", s.IPAddress,
+		"DhcpLanNetmask", s.Netmask,
+		"DhcpStatus", boolToString(s.Enabled),
+		"DhcpStartIPAddress", s.StartIP,
+		"DhcpEndIPAddress", s.EndIP,
+		"DhcpLeaseTime", fmt.Sprintf("%d", s.LeaseTime/time.Second),
+		"DnsStatus", fmt.Sprintf("%d", s.DNSMode),
+		"PrimaryDns", s.PrimaryDNS,
+		"SecondaryDns", s.SecondaryDNS,
+	}, d)...))
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	if s.IPAddress != cur.IPAddress {
+		err = c.followHost(cur.IPAddress, s.IPAddress)
+	}
+	return ok, err
+}
+
+// followHost changes the client URL host from oldHost to newHost, keeping the
+// scheme and port, and starts a new session. The URL is left unchanged when it
+// does not use oldHost.
+func (c *Client) followHost(oldHost, newHost string) error {
+	changed, err := c.changeHost(oldHost, newHost)
+	if err != nil || !changed {
+		return err
+	}
+	return c.startSession()
+}
+
+// changeHost changes the client URL host from oldHost to newHost, reporting
+// whether the URL was changed.
+func (c *Client) changeHost(oldHost, newHost string) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.url.Hostname() != oldHost {
+		return false, nil
+	}
+
+	u := *c.url
+	u.Host = newHost
+	if port := c.url.Port(); port != "" {
+		u.Host = net.JoinHostPort(newHost, port)
+	}
+
+	return true, URL(u.String())(c)
+}
+
+// StaticLease is a static DHCP lease, assigning a fixed IP address to a host.
+type StaticLease struct {
+	MACAddress string
+	IPAddress  string
+	Enabled    bool
+}
+
+// StaticLeases retrieves the static DHCP leases.
+func (c *Client) StaticLeases() ([]StaticLease, error) {
+	d, err := c.Do("api/dhcp/static-addr-info", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	leases := []StaticLease{}
+	if l, ok := d["Hosts"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["Host"]) {
+			leases = append(leases, StaticLease{
+				MACAddress: strings.ToUpper(xmlString(m, "HostHw")),
+				IPAddress:  xmlString(m, "HostIp"),
+				Enabled:    xmlString(m, "HostEnabled") == "1",
+			})
+		}
+	}
+
+	return leases, nil
+}
+
+// StaticLeasesSet replaces the static DHCP leases. MAC addresses are
+// validated and normalized, and duplicate MAC or IP addresses are rejected.
+func (c *Client) StaticLeasesSet(leases []StaticLease) (bool, error) {
+	macs, ips := make(map[string]bool), make(map[string]bool)
+
+	var hosts string
+	for i, l := range leases {
+		mac, err := ParseMAC(l.MACAddress)
+		if err != nil {
+			return false, err
+		}
+		ip, err := parseIPv4(l.IPAddress)
+		if err != nil {
+			return false, err
+		}
+		if macs[mac] || ips[ip.String()] {
+			return false, ErrInvalidValue
+		}
+		macs[mac], ips[ip.String()] = true, true
+
+		hosts += "    <Host>\n" + xmlPairsString("      ",
+			"HostIndex", fmt.Sprintf("%d", i+1),
+			"HostHw", mac,
+			"HostIp", ip.String(),
+			"HostEnabled", boolToString(l.Enabled),
+		) + "    </Host>\n"
+	}
+
+	return c.doReqCheckOK("api/dhcp/static-addr-info", SimpleRequestXML(
+		"Hosts", "\n"+hosts+"  ",
+	))
+}
+
+// StaticLeaseAdd adds a static DHCP lease, or changes the IP address of the
+// lease when the host already has one.
+func (c *Client) StaticLeaseAdd(mac, ip string) (bool, error) {
+	mac, err := ParseMAC(mac)
+	if err != nil {
+		return false, err
+	}
+
+	leases, err := c.StaticLeases()
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for i := range leases {
+		if leases[i].MACAddress == mac {
+			leases[i].IPAddress, leases[i].Enabled = ip, true
+			found = true
+		}
+	}
+	if !found {
+		leases = append(leases, StaticLease{MACAddress: mac, IPAddress: ip, Enabled: true})
+	}
+
+	return c.StaticLeasesSet(leases)
+}
+
+// StaticLeaseRemove removes the static DHCP lease of a host.
+func (c *Client) StaticLeaseRemove(mac string) (bool, error) {
+	mac, err := ParseMAC(mac)
+	if err != nil {
+		return false, err
+	}
+
+	leases, err := c.StaticLeases()
+	if err != nil {
+		return false, err
+	}
+
+	for i, l := range leases {
+		if l.MACAddress == mac {
+			return c.StaticLeasesSet(append(leases[:i], leases[i+1:]...))
+		}
+	}
+
+	return false, ErrStaticLeaseNotFound
+}
@@ -0,0 +1,106 @@
+package hilink
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConnectMode is the dialup connect mode.
+type ConnectMode int
+
+// ConnectMode values.
+const (
+	ConnectModeAuto ConnectMode = iota
+	ConnectModeManual
+	ConnectModeOnDemand
+)
+
+// connectModeNames are the names for the ConnectMode values.
+var connectModeNames = []string{"auto", "manual", "on-demand"}
+
+// String satisfies the fmt.Stringer interface.
+func (m ConnectMode) String() string {
+	if m >= 0 && int(m) < len(connectModeNames) {
+		return connectModeNames[m]
+	}
+	return fmt.Sprintf("unknown (%d)", int(m))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (m ConnectMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (m *ConnectMode) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for i, n := range connectModeNames {
+		if s == n || s == strconv.Itoa(i) {
+			*m = ConnectMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid connect mode %q", s)
+}
+
+// DialupSettings are the dialup connection settings.
+//
+// MaxIdleTime is the idle time in seconds after which an on-demand connection
+// is disconnected.
+type DialupSettings struct {
+	ConnectMode        ConnectMode
+	MTU                uint
+	MaxIdleTime        uint
+	RoamingAutoConnect bool
+	AutoDial           bool
+	AlwaysOn           bool
+}
+
+// parseDialupSettings converts the XMLData returned by ConnectionInfo into
+// DialupSettings.
+func parseDialupSettings(d XMLData) *DialupSettings {
+	return &DialupSettings{
+		ConnectMode:        ConnectMode(xmlInt(d, "ConnectMode")),
+		MTU:                uint(xmlInt(d, "MTU")),
+		MaxIdleTime:        uint(xmlInt(d, "MaxIdelTime")),
+		RoamingAutoConnect: xmlString(d, "RoamAutoConnectEnable") == "1",
+		AutoDial:           xmlString(d, "auto_dial_switch") == "1",
+		AlwaysOn:           xmlString(d, "pdp_always_on") == "1",
+	}
+}
+
+// DialupSettings retrieves the dialup connection settings.
+func (c *Client) DialupSettings() (*DialupSettings, error) {
+	d, err := c.ConnectionInfo()
+	if err != nil {
+		return nil, err
+	}
+	return parseDialupSettings(d), nil
+}
+
+// DialupSettingsSet changes the dialup connection settings. The current
+// settings are retrieved and passed to update, and the result is written back
+// to the device, preserving both the settings left untouched by update and
+// any additional settings reported by the firmware.
+func (c *Client) DialupSettingsSet(update func(*DialupSettings)) (bool, error) {
+	d, err := c.ConnectionInfo()
+	if err != nil {
+		return false, err
+	}
+
+	s := parseDialupSettings(d)
+	update(s)
+
+	// note: the order is important!
+	vals := xmlAppendExtra([]string{
+		"ConnectMode", fmt.Sprintf("%d", s.ConnectMode),
+		"MTU", fmt.Sprintf("%d", s.MTU),
+		"MaxIdelTime", fmt.Sprintf("%d", s.MaxIdleTime),
+		"RoamAutoConnectEnable", boolToString(s.RoamingAutoConnect),
+		"auto_dial_switch", boolToString(s.AutoDial),
+		"pdp_always_on", boolToString(s.AlwaysOn),
+	}, d)
+
+	return c.doReqCheckOK("api/dialup/connection", SimpleRequestXML(vals...))
+}
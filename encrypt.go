@@ -0,0 +1,104 @@
+package hilink
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+)
+
+// EncryptHeader is the header used by the WebUI to mark encrypted request
+// bodies.
+const EncryptHeader = "encrypt_transmit"
+
+// encryptedRequest is a request body encrypted with the device public key.
+type encryptedRequest string
+
+// encryptionEnabled determines if the device expects sensitive requests
+// (passwords, WiFi keys) to be encrypted.
+func (c *Client) encryptionEnabled() (bool, error) {
+	d, err := c.Do("api/global/module-switch", nil)
+	if err != nil {
+		return false, err
+	}
+	return xmlString(d, "encrypt_enabled") == "1", nil
+}
+
+// publicKey retrieves the device public key used for encrypted requests, and
+// whether OAEP padding is used.
+func (c *Client) publicKey() (*rsa.PublicKey, bool, error) {
+	d, err := c.Do("api/webserver/publickey", nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	n, ok := new(big.Int).SetString(xmlString(d, "encpubkeyn"), 16)
+	if !ok {
+		return nil, false, ErrInvalidResponse
+	}
+	e, err := strconv.ParseInt(xmlString(d, "encpubkeye"), 16, 32)
+	if err != nil {
+		return nil, false, ErrInvalidResponse
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e)}, xmlString(d, "rsapadingtype") == "1", nil
+}
+
+// encryptRequest encrypts a request body when the device requires encryption,
+// otherwise returning buf unchanged.
+//
+// As done by the WebUI, the body is base64 encoded, split into chunks fitting
+// the key size, and each chunk is RSA encrypted and hex encoded.
+func (c *Client) encryptRequest(buf []byte) (interface{}, error) {
+	enabled, err := c.encryptionEnabled()
+	if err != nil || !enabled {
+		return buf, err
+	}
+
+	pub, oaep, err := c.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	s := []byte(base64.StdEncoding.EncodeToString(buf))
+	size := pub.Size() - 11
+	if oaep {
+		size = pub.Size() - 2*sha1.Size - 2
+	}
+
+	var out string
+	for len(s) > 0 {
+		n := size
+		if n > len(s) {
+			n = len(s)
+		}
+
+		var enc []byte
+		if oaep {
+			enc, err = rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, s[:n], nil)
+		} else {
+			enc, err = rsa.EncryptPKCS1v15(rand.Reader, pub, s[:n])
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		out += hex.EncodeToString(enc)
+		s = s[n:]
+	}
+
+	return encryptedRequest(out), nil
+}
+
+// doReqCheckOKEncrypted wraps a request operation for a request body that
+// needs to be encrypted when required by the device.
+func (c *Client) doReqCheckOKEncrypted(path string, buf []byte) (bool, error) {
+	v, err := c.encryptRequest(buf)
+	if err != nil {
+		return false, err
+	}
+	return c.doReqCheckOK(path, v)
+}
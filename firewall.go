@@ -0,0 +1,203 @@
+package hilink
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FirewallSwitches are the firewall toggles of the device. The IP, MAC and URL
+// filters, as well as the WAN ping blocking, only apply when Firewall is
+// enabled.
+type FirewallSwitches struct {
+	Firewall  bool
+	IPFilter  bool
+	WanPing   bool
+	MACFilter bool
+	URLFilter bool
+}
+
+// FirewallSwitches retrieves the firewall toggles.
+func (c *Client) FirewallSwitches() (*FirewallSwitches, error) {
+	d, err := c.Do("api/security/firewall-switch", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FirewallSwitches{
+		Firewall:  xmlString(d, "FirewallMainSwitch") == "1",
+		IPFilter:  xmlString(d, "FirewallIPFilterSwitch") == "1",
+		WanPing:   xmlString(d, "FirewallWanPortPingSwitch") == "1",
+		MACFilter: xmlString(d, "firewallmacfilterswitch") == "1",
+		URLFilter: xmlString(d, "firewallurlfilterswitch") == "1",
+	}, nil
+}
+
+// FirewallSwitchesSet sets the firewall toggles, preserving any additional
+// toggles reported by the firmware.
+func (c *Client) FirewallSwitchesSet(s FirewallSwitches) (bool, error) {
+	d, err := c.Do("api/security/firewall-switch", nil)
+	if err != nil {
+		return false, err
+	}
+
+	// note: the order is important!
+	return c.doReqCheckOK("api/security/firewall-switch", SimpleRequestXML(xmlAppendExtra([]string{
+		"FirewallMainSwitch", boolToString(s.Firewall),
+		"FirewallIPFilterSwitch", boolToString(s.IPFilter),
+		"FirewallWanPortPingSwitch", boolToString(s.WanPing),
+		"firewallmacfilterswitch", boolToString(s.MACFilter),
+		"firewallurlfilterswitch", boolToString(s.URLFilter),
+	}, d)...))
+}
+
+// IPFilterRule is a LAN IP filter rule, blocking traffic from LAN hosts to WAN
+// hosts. Empty addresses and zero ports match any address or port, and a zero
+// end port matches the start port only.
+type IPFilterRule struct {
+	Enabled    bool
+	Protocol   Protocol
+	LanIP      string
+	LanPort    uint
+	LanEndPort uint
+	WanIP      string
+	WanPort    uint
+	WanEndPort uint
+}
+
+// validPortRange determines if start to end is a valid port range, where
+// zero ports are allowed.
+func validPortRange(start, end uint) bool {
+	return start <= 65535 && end <= 65535 && (end == 0 || end >= start)
+}
+
+// validate checks the rule is valid.
+func (r *IPFilterRule) validate() error {
+	switch r.Protocol {
+	case ProtocolBoth, ProtocolTCP, ProtocolUDP:
+	default:
+		return ErrInvalidValue
+	}
+	if !validPortRange(r.LanPort, r.LanEndPort) || !validPortRange(r.WanPort, r.WanEndPort) {
+		return ErrInvalidValue
+	}
+	for _, ip := range []string{r.LanIP, r.WanIP} {
+		if ip == "" {
+			continue
+		}
+		if _, err := parseIPv4(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatPort formats a port, where 0 is formatted as an empty string.
+func formatPort(port uint) string {
+	if port == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", port)
+}
+
+// IPFilterRules retrieves the LAN IP filter rules, in order.
+func (c *Client) IPFilterRules() ([]IPFilterRule, error) {
+	d, err := c.Do("api/security/lan-ip-filter", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []IPFilterRule{}
+	if l, ok := d["IPFilters"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["IPFilter"]) {
+			rules = append(rules, IPFilterRule{
+				Enabled:    xmlString(m, "LanIPFilterStatus") == "1",
+				Protocol:   Protocol(xmlInt(m, "LanIPFilterProtocol")),
+				LanIP:      xmlString(m, "LanIPFilterLanAddress"),
+				LanPort:    uint(xmlInt(m, "LanIPFilterLanStartPort")),
+				LanEndPort: uint(xmlInt(m, "LanIPFilterLanEndPort")),
+				WanIP:      xmlString(m, "LanIPFilterWanAddress"),
+				WanPort:    uint(xmlInt(m, "LanIPFilterWanStartPort")),
+				WanEndPort: uint(xmlInt(m, "LanIPFilterWanEndPort")),
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+// IPFilterRulesSet replaces the LAN IP filter rules, keeping their order.
+func (c *Client) IPFilterRulesSet(rules []IPFilterRule) (bool, error) {
+	var filters string
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			return false, err
+		}
+
+		// note: the order is important!
+		filters += "    <IPFilter>\n" + xmlPairsString("      ",
+			"Index", fmt.Sprintf("%d", i),
+			"LanIPFilterStatus", boolToString(r.Enabled),
+			"LanIPFilterProtocol", fmt.Sprintf("%d", r.Protocol),
+			"LanIPFilterLanAddress", r.LanIP,
+			"LanIPFilterLanStartPort", formatPort(r.LanPort),
+			"LanIPFilterLanEndPort", formatPort(r.LanEndPort),
+			"LanIPFilterWanAddress", r.WanIP,
+			"LanIPFilterWanStartPort", formatPort(r.WanPort),
+			"LanIPFilterWanEndPort", formatPort(r.WanEndPort),
+		) + "    </IPFilter>\n"
+	}
+
+	return c.doReqCheckOK("api/security/lan-ip-filter", SimpleRequestXML(
+		"IPFilters", "\n"+filters+"  ",
+	))
+}
+
+// URLFilterRule is a URL filter rule, blocking access to URLs containing URL.
+type URLFilterRule struct {
+	URL     string
+	Enabled bool
+}
+
+// URLFilterRules retrieves the URL filter rules, in order.
+func (c *Client) URLFilterRules() ([]URLFilterRule, error) {
+	d, err := c.Do("api/security/url-filter", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []URLFilterRule{}
+	if l, ok := d["urlfilters"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["urlfilter"]) {
+			rules = append(rules, URLFilterRule{
+				URL:     xmlString(m, "value"),
+				Enabled: xmlString(m, "status") == "1",
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+// URLFilterRulesSet replaces the URL filter rules, keeping their order.
+// Duplicate URLs are rejected.
+func (c *Client) URLFilterRulesSet(rules []URLFilterRule) (bool, error) {
+	seen := make(map[string]bool)
+
+	var filters string
+	for _, r := range rules {
+		u := strings.TrimSpace(r.URL)
+		if u == "" || seen[u] {
+			return false, ErrInvalidValue
+		}
+		seen[u] = true
+
+		filters += "    <urlfilter>\n" + xmlPairsString("      ",
+			"value", xmlEscape(u),
+			"status", boolToString(r.Enabled),
+		) + "    </urlfilter>\n"
+	}
+
+	return c.doReqCheckOK("api/security/url-filter", SimpleRequestXML(
+		"urlfilters", "\n"+filters+"  ",
+	))
+}
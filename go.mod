@@ -5,4 +5,6 @@ go 1.13
 require (
 	github.com/clbanning/mxj v1.8.4
 	github.com/gorilla/mux v1.7.4
+	go.etcd.io/bbolt v1.3.5
+	gopkg.in/yaml.v2 v2.4.0
 )
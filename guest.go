@@ -0,0 +1,238 @@
+package hilink
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrGuestWlanNotSupported is the error returned for devices without a
+	// guest WiFi network on the multi-SSID API.
+	ErrGuestWlanNotSupported = errors.New("guest wlan not supported")
+
+	// ErrWlanScheduleNotSupported is the error returned for devices without
+	// the WiFi time rule API.
+	ErrWlanScheduleNotSupported = errors.New("wlan schedule not supported")
+)
+
+// GuestWlanIndex is the SSID index of the guest WiFi network.
+const GuestWlanIndex = 1
+
+// GuestWlan are the settings of the guest WiFi network.
+//
+// TimeLimit is the time after which the device disables the guest network
+// again, in whole hours, or 0 to keep it enabled.
+type GuestWlan struct {
+	Enabled   bool
+	SSID      string
+	AuthMode  WlanAuthMode
+	Key       string
+	TimeLimit time.Duration
+}
+
+// guestWlanState retrieves the WLAN configuration and the position of the
+// guest network in it.
+func (c *Client) guestWlanState() (*wlanState, int, error) {
+	w, err := c.wlanState()
+	if err != nil {
+		return nil, 0, err
+	}
+	if w.multi() {
+		for i, s := range w.settings {
+			if s.Index == GuestWlanIndex {
+				return w, i, nil
+			}
+		}
+	}
+	return nil, 0, ErrGuestWlanNotSupported
+}
+
+// GuestWlan retrieves the guest WiFi settings. The guest network is the
+// second SSID (GuestWlanIndex) of devices with multi-SSID support. The
+// dedicated guest network API (api/wlan/guest-network) of some firmwares is
+// not supported: ErrGuestWlanNotSupported is returned for other devices.
+func (c *Client) GuestWlan() (*GuestWlan, error) {
+	w, i, err := c.guestWlanState()
+	if err != nil {
+		return nil, err
+	}
+
+	s := w.settings[i]
+	return &GuestWlan{
+		Enabled:   s.Enabled,
+		SSID:      s.SSID,
+		AuthMode:  s.AuthMode,
+		Key:       s.Key,
+		TimeLimit: time.Duration(xmlInt(w.ssids[i], "wifiguestofftime")) * time.Hour,
+	}, nil
+}
+
+// GuestWlanSet changes the guest WiFi settings. ErrGuestWlanNotSupported is
+// returned for devices without a guest network on the multi-SSID API.
+func (c *Client) GuestWlanSet(g GuestWlan) (bool, error) {
+	if g.TimeLimit < 0 || g.TimeLimit%time.Hour != 0 {
+		return false, ErrInvalidValue
+	}
+
+	w, i, err := c.guestWlanState()
+	if err != nil {
+		return false, err
+	}
+
+	s := w.settings[i]
+	s.Enabled, s.SSID, s.AuthMode, s.Key = g.Enabled, g.SSID, g.AuthMode, g.Key
+	if err = s.validate(); err != nil {
+		return false, err
+	}
+
+	return c.wlanMultiSet(w, i, s,
+		"wifiguestofftime", fmt.Sprintf("%d", g.TimeLimit/time.Hour),
+	)
+}
+
+// GuestWlanEnable enables or disables the guest WiFi, keeping its settings.
+func (c *Client) GuestWlanEnable(enabled bool) (bool, error) {
+	g, err := c.GuestWlan()
+	if err != nil {
+		return false, err
+	}
+	g.Enabled = enabled
+	return c.GuestWlanSet(*g)
+}
+
+// WlanTimeRule is a weekly WiFi schedule rule: on the listed days, the WiFi is
+// switched off at Off and back on at On (both "15:04" formatted). When On is
+// before Off, the WiFi stays off past midnight.
+type WlanTimeRule struct {
+	Enabled bool
+	Days    []time.Weekday
+	Off     string
+	On      string
+}
+
+// validate checks the rule is valid.
+func (r *WlanTimeRule) validate() error {
+	if len(r.Days) == 0 {
+		return ErrInvalidValue
+	}
+	for _, d := range r.Days {
+		if d < time.Sunday || d > time.Saturday {
+			return ErrInvalidValue
+		}
+	}
+	for _, s := range []string{r.Off, r.On} {
+		if _, err := time.Parse("15:04", s); err != nil {
+			return ErrInvalidValue
+		}
+	}
+	return nil
+}
+
+// WlanSchedule is the weekly WiFi on/off schedule.
+type WlanSchedule struct {
+	Enabled bool
+	Rules   []WlanTimeRule
+}
+
+// parseWeekdays parses a comma separated list of days (0 is Sunday).
+func parseWeekdays(s string) []time.Weekday {
+	var days []time.Weekday
+	for _, f := range strings.Split(s, ",") {
+		if i, err := strconv.Atoi(strings.TrimSpace(f)); err == nil && i >= 0 && i <= 6 {
+			days = append(days, time.Weekday(i))
+		}
+	}
+	return days
+}
+
+// formatWeekdays formats days as a sorted, comma separated list.
+func formatWeekdays(days []time.Weekday) string {
+	var l []string
+	seen := make(map[time.Weekday]bool)
+	for _, d := range days {
+		if !seen[d] {
+			seen[d] = true
+			l = append(l, strconv.Itoa(int(d)))
+		}
+	}
+	sort.Strings(l)
+	return strings.Join(l, ",")
+}
+
+// wlanScheduleErr converts the not supported device error of the WiFi time
+// rule API into ErrWlanScheduleNotSupported.
+func wlanScheduleErr(err error) error {
+	if IsErrorCode(err, ErrorCodeNotSupported) {
+		return ErrWlanScheduleNotSupported
+	}
+	return err
+}
+
+// WlanSchedule retrieves the weekly WiFi on/off schedule, using the WiFi time
+// rule API (api/wlan/wifi-timerule). The WiFi time switch API
+// (api/wlan/wlan-time-switch) of some firmwares is not supported:
+// ErrWlanScheduleNotSupported is returned for devices without the time rule
+// API.
+func (c *Client) WlanSchedule() (*WlanSchedule, error) {
+	d, err := c.Do("api/wlan/wifi-timerule", nil)
+	if err != nil {
+		return nil, wlanScheduleErr(err)
+	}
+
+	s := &WlanSchedule{
+		Enabled: xmlString(d, "enable") == "1",
+		Rules:   []WlanTimeRule{},
+	}
+	if l, ok := d["TimeRules"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["TimeRule"]) {
+			s.Rules = append(s.Rules, WlanTimeRule{
+				Enabled: xmlString(m, "Enable") == "1",
+				Days:    parseWeekdays(xmlString(m, "RepeatDays")),
+				Off:     xmlString(m, "StartTime"),
+				On:      xmlString(m, "EndTime"),
+			})
+		}
+	}
+
+	return s, nil
+}
+
+// WlanScheduleSet replaces the weekly WiFi on/off schedule.
+// ErrWlanScheduleNotSupported is returned for devices without the WiFi time
+// rule API.
+func (c *Client) WlanScheduleSet(s WlanSchedule) (bool, error) {
+	var rules string
+	for i, r := range s.Rules {
+		if err := r.validate(); err != nil {
+			return false, err
+		}
+		rules += "    <TimeRule>\n" + xmlPairsString("      ",
+			"Index", fmt.Sprintf("%d", i),
+			"Enable", boolToString(r.Enabled),
+			"StartTime", r.Off,
+			"EndTime", r.On,
+			"RepeatDays", formatWeekdays(r.Days),
+		) + "    </TimeRule>\n"
+	}
+
+	ok, err := c.doReqCheckOK("api/wlan/wifi-timerule", SimpleRequestXML(
+		"enable", boolToString(s.Enabled),
+		"TimeRules", "\n"+rules+"  ",
+	))
+	return ok, wlanScheduleErr(err)
+}
+
+// WlanScheduleEnable enables or disables the weekly WiFi on/off schedule,
+// keeping its rules.
+func (c *Client) WlanScheduleEnable(enabled bool) (bool, error) {
+	s, err := c.WlanSchedule()
+	if err != nil {
+		return false, err
+	}
+	s.Enabled = enabled
+	return c.WlanScheduleSet(*s)
+}
@@ -45,6 +45,8 @@ type Client struct {
 	client    *http.Client
 	token     string
 	transport http.RoundTripper
+	redactor  *Redactor
+	hooks     []Hook
 
 	sync.Mutex
 }
@@ -58,6 +60,7 @@ func NewClient(opts ...Option) (*Client, error) {
 		client: &http.Client{
 			Timeout: DefaultTimeout,
 		},
+		redactor: DefaultRedactor(),
 	}
 
 	// process options
@@ -78,26 +81,32 @@ func NewClient(opts ...Option) (*Client, error) {
 
 	// start session
 	if !c.nostart {
-		// retrieve session id
-		sessID, tokID, err := c.NewSessionAndTokenID()
+		err = c.startSession()
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		// set session id
-		err = c.SetSessionAndTokenID(sessID, tokID)
-		if err != nil {
-			return nil, err
-		}
+	return c, nil
+}
 
-		// try login, ignore the OK value
-		_, err = c.login()
-		if err != nil {
-			return nil, err
-		}
+// startSession starts a new session with the server and logs in.
+func (c *Client) startSession() error {
+	// retrieve session id
+	sessID, tokID, err := c.NewSessionAndTokenID()
+	if err != nil {
+		return err
 	}
 
-	return c, nil
+	// set session id
+	err = c.SetSessionAndTokenID(sessID, tokID)
+	if err != nil {
+		return err
+	}
+
+	// try login, ignore the OK value
+	_, err = c.login()
+	return err
 }
 
 // createRequest creates a request for use with the Client.
@@ -122,6 +131,9 @@ func (c *Client) createRequest(urlstr string, v interface{}) (*http.Request, err
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
 	req.Header[TokenHeader] = []string{c.token}
 	req.Header["_ResponseSource"] = []string{"Broswer"}
+	if _, ok := v.(encryptedRequest); ok {
+		req.Header[EncryptHeader] = []string{EncryptHeader}
+	}
 
 	return req, nil
 }
@@ -151,17 +163,29 @@ func (c *Client) createJsonRequest(urlstr string, v string) (*http.Request, erro
 // doReq sends a request to the server with the provided path. If data is nil,
 // then GET will be used as the HTTP method, otherwise POST will be used.
 func (c *Client) doReq(path string, v interface{}, takeFirstEl bool) (interface{}, error) {
+	return c.doReqChecked(path, v, takeFirstEl, nil)
+}
+
+// doReqChecked sends a request like doReq, validating the decoded response
+// with check (when not nil) before notifying the hooks, so that responses
+// rejected by check are reported to the hooks as errors.
+func (c *Client) doReqChecked(path string, v interface{}, takeFirstEl bool, check func(interface{}) error) (res interface{}, err error) {
 	c.Lock()
 	defer c.Unlock()
 
-	var err error
-
 	// create http request
 	q, err := c.createRequest(c.rawurl+path, v)
 	if err != nil {
 		return nil, err
 	}
 
+	// notify hooks
+	var status int
+	done := c.observe(q.Method, path, v)
+	defer func() {
+		done(status, res, err)
+	}()
+
 	// do request
 	r, err := c.client.Do(q)
 	if err != nil {
@@ -170,6 +194,7 @@ func (c *Client) doReq(path string, v interface{}, takeFirstEl bool) (interface{
 	defer r.Body.Close()
 
 	// check status code
+	status = r.StatusCode
 	if r.StatusCode != http.StatusOK {
 		return nil, ErrBadStatusCode
 	}
@@ -192,6 +217,13 @@ func (c *Client) doReq(path string, v interface{}, takeFirstEl bool) (interface{
 		return nil, err
 	}
 
+	// validate
+	if check != nil {
+		if err := check(m); err != nil {
+			return nil, err
+		}
+	}
+
 	return m, nil
 }
 
@@ -225,18 +257,27 @@ func (c *Client) doReqString(path string, v interface{}, elName string) (string,
 
 // doReqString wraps a request operation, returning the data of the specified
 // child node named elName as a string.
-func (c *Client) doJsonReqString(path string, v string) (string, error) {
+func (c *Client) doJsonReqString(path string, v string) (res string, err error) {
 	c.Lock()
 	defer c.Unlock()
 
-	var err error
-
 	// create http request
 	q, err := c.createJsonRequest(c.rawurl+path, v)
 	if err != nil {
 		return "", err
 	}
 
+	// notify hooks
+	var status int
+	var payload interface{}
+	if v != "" {
+		payload = v
+	}
+	done := c.observe(q.Method, path, payload)
+	defer func() {
+		done(status, res, err)
+	}()
+
 	// do request
 	r, err := c.client.Do(q)
 	if err != nil {
@@ -245,6 +286,7 @@ func (c *Client) doJsonReqString(path string, v string) (string, error) {
 	defer r.Body.Close()
 
 	// check status code
+	status = r.StatusCode
 	if r.StatusCode != http.StatusOK {
 		return "", ErrBadStatusCode
 	}
@@ -269,31 +311,42 @@ func (c *Client) doJsonReqString(path string, v string) (string, error) {
 // doReqCheckOK wraps a request operation (ie, connect, disconnect, etc),
 // checking success via the presence of 'OK' in the XML <response/>.
 func (c *Client) doReqCheckOK(path string, v interface{}) (bool, error) {
-	res, err := c.doReq(path, v, false)
-	if err != nil {
+	_, err := c.doReqChecked(path, v, false, checkResponseOK)
+	switch {
+	case err == ErrNotOK:
+		return false, nil
+	case err != nil:
 		return false, err
 	}
+	return true, nil
+}
 
+// checkResponseOK checks the presence of 'OK' in the XML <response/>,
+// returning ErrNotOK when the response is not OK.
+func checkResponseOK(res interface{}) error {
 	// expect mxj.Map
 	m, ok := res.(mxj.Map)
 	if !ok {
-		return false, ErrInvalidResponse
+		return ErrInvalidResponse
 	}
 
 	// check response present
 	o := map[string]interface{}(m)
 	r, ok := o["response"]
 	if !ok {
-		return false, ErrInvalidResponse
+		return ErrInvalidResponse
 	}
 
 	// convert
 	s, ok := r.(string)
 	if !ok {
-		return false, ErrInvalidValue
+		return ErrInvalidValue
 	}
 
-	return s == "OK", nil
+	if s != "OK" {
+		return ErrNotOK
+	}
+	return nil
 }
 
 // login authentifies the user using the user identifier and password given
@@ -497,21 +550,20 @@ func (c *Client) DeviceReset() (bool, error) {
 	return c.DeviceControl(2)
 }
 
-// DeviceBackup backups device configuration and retrieves backed up
-// configuration data as a base64 encoded string.
-func (c *Client) DeviceBackup() (string, error) {
+// DeviceBackup backups device configuration and retrieves the backed up
+// configuration data (the nvram.bak file).
+func (c *Client) DeviceBackup() ([]byte, error) {
 	// cause backup to be generated
 	ok, err := c.DeviceControl(3)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if !ok {
-		return "", errors.New("unable to backup device configuration")
+		return nil, errors.New("unable to backup device configuration")
 	}
 
 	// retrieve data
-	//res, err := c.doReq("nvram.bak")
-	return " -- not implemented -- ", nil
+	return c.doReqRaw("nvram.bak")
 }
 
 // DeviceShutdown shuts down the device.
@@ -561,20 +613,27 @@ func (c *Client) ConnectionInfo() (XMLData, error) {
 	return c.Do("api/dialup/connection", nil)
 }
 
-// doReqConn wraps a connection manipulation request.
-func (c *Client) ConnectionProfile(roaming, maxIdleTime string,
+// ConnectionProfile sets the connection (dialup) roaming auto connect ("0" or
+// "1") and max idle time (in seconds) settings. Empty values are left
+// unchanged, as are the other dialup settings (see DialupSettingsSet).
+func (c *Client) ConnectionProfile(roaming, maxIdleTime string) (bool, error) {
+	var idle uint64
+	if maxIdleTime != "" {
+		var err error
+		idle, err = strconv.ParseUint(maxIdleTime, 10, 32)
+		if err != nil {
+			return false, ErrInvalidValue
+		}
+	}
 
-// connectMode, autoReconnect, roamAutoConnect, roamAutoReconnect string,
-// interval, idle int,
-) (bool, error) {
-	return c.doReqCheckOK("api/dialup/connection", SimpleRequestXML(
-		"ConnectMode", "0",
-		"MTU", "1500",
-		"MaxIdelTime", maxIdleTime,
-		"RoamAutoConnectEnable", roaming,
-		"auto_dial_switch", "1",
-		"pdp_always_on", "0",
-	))
+	return c.DialupSettingsSet(func(s *DialupSettings) {
+		if roaming != "" {
+			s.RoamingAutoConnect = roaming == "1"
+		}
+		if maxIdleTime != "" {
+			s.MaxIdleTime = uint(idle)
+		}
+	})
 }
 
 // GlobalFeatures retrieves global feature information.
@@ -665,6 +724,22 @@ func (c *Client) ModeSet(netMode, netBand, lteBand string) (bool, error) {
 	))
 }
 
+// NetworkRegisterInfo retrieves network registration (PLMN selection)
+// information.
+func (c *Client) NetworkRegisterInfo() (XMLData, error) {
+	return c.Do("api/net/register", nil)
+}
+
+// NetworkRegister (re-)registers the device with a network provider (mode
+// values: 0-auto, 1-manual). The plmn and rat are only used in manual mode.
+func (c *Client) NetworkRegister(mode uint, plmn, rat string) (bool, error) {
+	return c.doReqCheckOK("api/net/register", SimpleRequestXML(
+		"Mode", fmt.Sprintf("%d", mode),
+		"Plmn", plmn,
+		"Rat", rat,
+	))
+}
+
 // PinInfo retrieves SIM PIN status information.
 func (c *Client) PinInfo() (XMLData, error) {
 	return c.Do("api/pin/status", nil)
@@ -762,40 +837,21 @@ func (c *Client) ProfileInfo() (XMLData, error) {
 	return c.Do("api/dialup/profiles", nil)
 }
 
-// Add connection profile
+// ProfileAdd adds a connection profile using PDP type IPv4 and no
+// authentication. See ProfileCreate for the other profile settings.
 func (c *Client) ProfileAdd(name string, apn string, user string, password string, isDefault bool) (bool, error) {
-	var newDefaultValue string
-	if isDefault {
-		newDefaultValue = "0"
-	} else {
-		newDefaultValue = "1"
-	}
-	return c.doReqCheckOK("api/dialup/profiles", XMLData{
-		"Delete":     0,
-		"SetDefault": newDefaultValue,
-		"Modify":     1,
-		"Profile": XMLData{
-			"Index":        "", //original is new_index
-			"IsValid":      1,
-			"Name":         name,
-			"ApnIsStatic":  "1",
-			"ApnName":      apn,
-			"DialupNum":    "*99#",
-			"Username":     user,
-			"Password":     password,
-			"AuthMode":     "0",
-			"IpIsStatic":   "",
-			"IpAddress":    "",
-			"DnsIsStatic":  "",
-			"PrimaryDns":   "",
-			"SecondaryDns": "",
-			"ReadOnly":     "0",
-			"iptype":       "0",
-		},
+	return c.ProfileCreate(Profile{
+		Name:      name,
+		ApnName:   apn,
+		Username:  user,
+		Password:  password,
+		IsDefault: isDefault,
 	})
 }
 
-// Delete connection profile
+// ProfileDelete deletes a connection profile, setting newDefault as the
+// default profile ("0" when there is no profile left). See ProfileRemove for
+// the typed variant.
 func (c *Client) ProfileDelete(index, newDefault string) (bool, error) {
 	return c.doReqCheckOK("api/dialup/profiles", SimpleRequestXML(
 		"Delete", index,
@@ -1031,14 +1087,14 @@ func (c *Client) UpnpSet(enabled bool) (bool, error) {
 	))
 }
 
-// Approve privacy policy.
+// Confirm privacy policy
 func (c *Client) PrivacyPolicy(agree bool) (string, error) {
 	return c.DoJson("api/app/privacypolicy",
 		"{\"data\": {\"Approve\": \"2\", \"Liscence\": \"0\"}}",
 	)
 }
 
-// Configure auto update.
+// Configure auto update of modem firmware
 func (c *Client) AutoUpdate(enabled bool) (XMLData, error) {
 	return c.Do("api/online-update/autoupdate-config", SimpleRequestXML(
 		"auto_update", boolToString(enabled),
@@ -1046,14 +1102,14 @@ func (c *Client) AutoUpdate(enabled bool) (XMLData, error) {
 	))
 }
 
-// Configure auto update.
+// Set basic device info to restore
 func (c *Client) BasicDeviceInfo(restore bool) (XMLData, error) {
 	return c.Do("api/device/basic_information", SimpleRequestXML(
 		"restore_default_status", boolToString(restore),
 	))
 }
 
-// Configure auto update.
+// Configure online update config
 func (c *Client) OnlineUpdateConfig(autoUpdateEnabled bool, serverForceEnabled bool) (XMLData, error) {
 	return c.Do("api/online-update/configuration", SimpleRequestXML(
 		"autoUpdateInterval", "1",
@@ -1061,7 +1117,7 @@ func (c *Client) OnlineUpdateConfig(autoUpdateEnabled bool, serverForceEnabled b
 	))
 }
 
-// Info auto update.
+// Online update config info
 func (c *Client) OnlineUpdateInfo() (XMLData, error) {
 	return c.Do("api/online-update/configuration", nil)
 }
@@ -1069,6 +1125,4 @@ func (c *Client) OnlineUpdateInfo() (XMLData, error) {
 // TODO:
 // UserLogin/UserLogout/UserPasswordChange
 //
-// WLAN management
-// firewall ("security") configuration
 // wifi profile management
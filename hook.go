@@ -0,0 +1,108 @@
+package hilink
+
+import (
+	"time"
+)
+
+// Event describes a request made by the client, as passed to hooks.
+//
+// Path is the API path (eg, api/device/information), and Request the request
+// payload (nil for GET requests). Duration, Status (the HTTP status code),
+// Code (the device error code), Response (the decoded response) and Err are
+// only set once the request completed. The same Event is passed to
+// OnRequest and to OnResponse or OnError, allowing hooks to correlate them.
+//
+// Payloads are not redacted; use Redactor.XMLData where needed.
+type Event struct {
+	Path     string
+	Method   string
+	Start    time.Time
+	Duration time.Duration
+	Status   int
+	Code     string
+	Request  interface{}
+	Response interface{}
+	Err      error
+}
+
+// Hook observes the requests made by a client. OnRequest is called before a
+// request is sent, and OnResponse or OnError once it completed. OnError is
+// called for transport errors, bad HTTP status codes, device errors and
+// requests not answered with OK (ErrNotOK) alike.
+//
+// Hooks are called synchronously while the client is locked, and must not use
+// the client.
+type Hook interface {
+	OnRequest(*Event)
+	OnResponse(*Event)
+	OnError(*Event)
+}
+
+// HookFuncs is a Hook calling the non-nil funcs.
+type HookFuncs struct {
+	Request  func(*Event)
+	Response func(*Event)
+	Error    func(*Event)
+}
+
+// OnRequest satisfies the Hook interface.
+func (h HookFuncs) OnRequest(e *Event) {
+	if h.Request != nil {
+		h.Request(e)
+	}
+}
+
+// OnResponse satisfies the Hook interface.
+func (h HookFuncs) OnResponse(e *Event) {
+	if h.Response != nil {
+		h.Response(e)
+	}
+}
+
+// OnError satisfies the Hook interface.
+func (h HookFuncs) OnError(e *Event) {
+	if h.Error != nil {
+		h.Error(e)
+	}
+}
+
+// Hooks is an option adding hooks observing the requests made by the client.
+func Hooks(hooks ...Hook) Option {
+	return func(c *Client) error {
+		c.hooks = append(c.hooks, hooks...)
+		return nil
+	}
+}
+
+// observe notifies the hooks of a request, returning the func notifying them
+// of its completion.
+func (c *Client) observe(method, path string, v interface{}) func(status int, res interface{}, err error) {
+	if len(c.hooks) == 0 {
+		return func(int, interface{}, error) {}
+	}
+
+	e := &Event{
+		Path:    path,
+		Method:  method,
+		Start:   time.Now(),
+		Request: v,
+	}
+	for _, h := range c.hooks {
+		h.OnRequest(e)
+	}
+
+	return func(status int, res interface{}, err error) {
+		e.Duration = time.Since(e.Start)
+		e.Status, e.Response, e.Err = status, res, err
+		if de, ok := err.(*Error); ok {
+			e.Code = de.Code
+		}
+		for _, h := range c.hooks {
+			if err != nil {
+				h.OnError(e)
+			} else {
+				h.OnResponse(e)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package hilink
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name string
+		res  string
+		err  error
+		code string
+	}{
+		{"ok", `<response>OK</response>`, nil, ""},
+		{"not ok", `<response>FAIL</response>`, ErrNotOK, ""},
+		{"invalid", `<response><Foo>1</Foo></response>`, ErrInvalidValue, ""},
+		{"device error", `<error><code>100002</code><message></message></error>`, nil, ErrorCodeNotSupported},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>`+test.res)
+			}))
+			defer s.Close()
+
+			var responses, errors []*Event
+			client, err := NewClient(URL(s.URL), NoSessionStart, Hooks(HookFuncs{
+				Response: func(e *Event) { responses = append(responses, e) },
+				Error:    func(e *Event) { errors = append(errors, e) },
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = CheckOK(client.doReqCheckOK("api/test", SimpleRequestXML("Foo", "1")))
+			switch {
+			case test.code != "" && !IsErrorCode(err, test.code):
+				t.Errorf("expected error code %s, got: %v", test.code, err)
+			case test.code == "" && err != test.err:
+				t.Errorf("expected %v, got: %v", test.err, err)
+			}
+
+			if err == nil && (len(responses) != 1 || len(errors) != 0) {
+				t.Fatalf("expected one response event, got: %d responses, %d errors", len(responses), len(errors))
+			}
+			if err != nil && (len(responses) != 0 || len(errors) != 1) {
+				t.Fatalf("expected one error event, got: %d responses, %d errors", len(responses), len(errors))
+			}
+			if err != nil {
+				if e := errors[0]; e.Path != "api/test" || e.Code != test.code || e.Err == nil {
+					t.Errorf("expected error event for api/test with code %q, got: %+v", test.code, e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package hilink
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HostInterface is the interface through which a host is connected.
+type HostInterface int
+
+// HostInterface values.
+const (
+	HostInterfaceUnknown HostInterface = iota
+	HostInterfaceWLAN
+	HostInterfaceLAN
+)
+
+// hostInterfaceNames are the names for the HostInterface values.
+var hostInterfaceNames = []string{"unknown", "wlan", "lan"}
+
+// String satisfies the fmt.Stringer interface.
+func (i HostInterface) String() string {
+	if i >= 0 && int(i) < len(hostInterfaceNames) {
+		return hostInterfaceNames[i]
+	}
+	return fmt.Sprintf("unknown (%d)", int(i))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (i HostInterface) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// Host is a host connected to the LAN or WiFi of the device.
+//
+// AssociatedTime is the time since the host connected, and Signal the signal
+// strength of a WiFi host in dBm, or 0 when not reported by the firmware.
+type Host struct {
+	MACAddress     string
+	IPAddress      string
+	Hostname       string
+	Interface      HostInterface
+	SSID           string
+	AssociatedTime time.Duration
+	Signal         int
+}
+
+// parseHost converts a <Host/> element into a Host.
+func parseHost(m map[string]interface{}) Host {
+	h := Host{
+		MACAddress:     strings.ToUpper(xmlString(m, "MacAddress")),
+		Hostname:       xmlString(m, "HostName"),
+		SSID:           xmlString(m, "AssociatedSsid"),
+		AssociatedTime: xmlSeconds(m, "AssociatedTime"),
+		Signal:         xmlInt(m, "Rssi"),
+	}
+
+	// newer firmware reports all addresses, separated by a ';'
+	h.IPAddress = strings.Split(xmlString(m, "IpAddress"), ";")[0]
+
+	switch strings.ToLower(xmlString(m, "InterfaceType")) {
+	case "wireless", "wlan", "wifi":
+		h.Interface = HostInterfaceWLAN
+	case "ethernet", "lan":
+		h.Interface = HostInterfaceLAN
+	}
+
+	return h
+}
+
+// HostList retrieves the hosts connected to the device. On firmware without
+// the LAN host information API, only WiFi hosts are reported.
+func (c *Client) HostList() ([]Host, error) {
+	d, err := c.Do("api/lan/HostInfo", nil)
+	lan := err == nil
+	if IsErrorCode(err, ErrorCodeNotSupported) || err == ErrBadStatusCode {
+		// older firmware either rejects or does not serve the path
+		d, err = c.Do("api/wlan/host-list", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []Host
+	if l, ok := d["Hosts"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["Host"]) {
+			// the LAN host information includes hosts that left
+			if lan && xmlString(m, "Active") == "0" {
+				continue
+			}
+			h := parseHost(m)
+			if !lan {
+				h.Interface = HostInterfaceWLAN
+			}
+			hosts = append(hosts, h)
+		}
+	}
+
+	return hosts, nil
+}
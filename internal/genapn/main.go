@@ -0,0 +1,79 @@
+// Command genapn generates the bundled APN database (apndb.go) from
+// apn.yaml. It is run by go generate from the hilink package directory.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"strconv"
+
+	"github.com/jpunie/hilink"
+)
+
+var (
+	flagIn  = flag.String("i", "apn.yaml", "in file")
+	flagOut = flag.String("o", "apndb.go", "out file")
+)
+
+func main() {
+	flag.Parse()
+
+	db, err := hilink.LoadApnDatabaseFile(*flagIn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(hdr)
+
+	buf.WriteString("// defaultApnEntries are the entries of the bundled APN database.\n")
+	buf.WriteString("var defaultApnEntries = []ApnEntry{\n")
+	for _, e := range db.Entries {
+		buf.WriteString("{")
+		for _, f := range []struct {
+			name, value string
+		}{
+			{"IMSIPrefix", e.IMSIPrefix},
+			{"ICCIDPrefix", e.ICCIDPrefix},
+			{"Operator", e.Operator},
+			{"Name", e.Name},
+			{"ApnName", e.ApnName},
+			{"Username", e.Username},
+			{"Password", e.Password},
+		} {
+			if f.value != "" {
+				fmt.Fprintf(buf, "%s: %s, ", f.name, strconv.Quote(f.value))
+			}
+		}
+		if e.AuthMode != hilink.AuthModeNone {
+			fmt.Fprintf(buf, "AuthMode: AuthMode(%d), ", e.AuthMode)
+		}
+		if e.PDPType != hilink.PDPTypeIPv4 {
+			fmt.Fprintf(buf, "PDPType: PDPType(%d), ", e.PDPType)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	if err = ioutil.WriteFile(*flagOut, buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	cmd := exec.Command("gofmt", "-s", "-w", *flagOut)
+	err = cmd.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+const (
+	hdr = `package hilink
+
+// Code generated by internal/genapn. DO NOT EDIT.
+
+`
+)
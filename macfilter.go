@@ -0,0 +1,217 @@
+package hilink
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidMACAddress is the invalid MAC address error.
+	ErrInvalidMACAddress = errors.New("invalid MAC address")
+
+	// ErrMacFilterFull is the MAC filter full error.
+	ErrMacFilterFull = errors.New("mac filter is full")
+
+	// ErrMacFilterNotFound is the MAC filter entry not found error.
+	ErrMacFilterNotFound = errors.New("mac filter entry not found")
+)
+
+// MacFilterMaxEntries is the maximum number of MAC filter entries supported by
+// the WebUI.
+const MacFilterMaxEntries = 10
+
+// MacFilterMode is the WiFi MAC filter mode.
+type MacFilterMode int
+
+// MacFilterMode values.
+const (
+	MacFilterDisabled MacFilterMode = iota
+	MacFilterAllow
+	MacFilterDeny
+)
+
+// macFilterModeNames are the names for the MacFilterMode values.
+var macFilterModeNames = []string{"disabled", "allow", "deny"}
+
+// String satisfies the fmt.Stringer interface.
+func (m MacFilterMode) String() string {
+	if m >= 0 && int(m) < len(macFilterModeNames) {
+		return macFilterModeNames[m]
+	}
+	return fmt.Sprintf("unknown (%d)", int(m))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (m MacFilterMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (m *MacFilterMode) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for i, n := range macFilterModeNames {
+		if s == n || s == strconv.Itoa(i) {
+			*m = MacFilterMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid mac filter mode %q", s)
+}
+
+// ParseMAC validates a MAC address, returning it in the upper case, colon
+// separated format used by the WebUI.
+func ParseMAC(s string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(s))
+	if err != nil || len(hw) != 6 {
+		return "", ErrInvalidMACAddress
+	}
+	return strings.ToUpper(hw.String()), nil
+}
+
+// MacFilterEntry is a WiFi MAC filter entry.
+type MacFilterEntry struct {
+	MACAddress string
+	Hostname   string
+}
+
+// MacFilter is the WiFi MAC filter: with MacFilterAllow only the listed hosts
+// can connect, with MacFilterDeny the listed hosts are blocked.
+type MacFilter struct {
+	Mode    MacFilterMode
+	Entries []MacFilterEntry
+}
+
+// index returns the index of the entry for mac, or -1.
+func (f *MacFilter) index(mac string) int {
+	for i, e := range f.Entries {
+		if e.MACAddress == mac {
+			return i
+		}
+	}
+	return -1
+}
+
+// MacFilter retrieves the WiFi MAC filter.
+func (c *Client) MacFilter() (*MacFilter, error) {
+	d, err := c.Do("api/wlan/mac-filter", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &MacFilter{
+		Mode:    MacFilterMode(xmlInt(d, "WifiMacFilterStatus")),
+		Entries: []MacFilterEntry{},
+	}
+	for i := 0; i < MacFilterMaxEntries; i++ {
+		mac := xmlString(d, fmt.Sprintf("WifiMacFilterMac%d", i))
+		if mac == "" {
+			continue
+		}
+		f.Entries = append(f.Entries, MacFilterEntry{
+			MACAddress: strings.ToUpper(mac),
+			Hostname:   xmlString(d, fmt.Sprintf("wifihostname%d", i)),
+		})
+	}
+
+	return f, nil
+}
+
+// MacFilterSet replaces the WiFi MAC filter. MAC addresses are validated and
+// normalized, and duplicates are rejected.
+func (c *Client) MacFilterSet(f MacFilter) (bool, error) {
+	if f.Mode < MacFilterDisabled || f.Mode > MacFilterDeny {
+		return false, ErrInvalidValue
+	}
+	if len(f.Entries) > MacFilterMaxEntries {
+		return false, ErrMacFilterFull
+	}
+
+	seen := make(map[string]bool)
+	vals := []string{"WifiMacFilterStatus", fmt.Sprintf("%d", f.Mode)}
+	for i := 0; i < MacFilterMaxEntries; i++ {
+		var e MacFilterEntry
+		if i < len(f.Entries) {
+			e = f.Entries[i]
+			mac, err := ParseMAC(e.MACAddress)
+			if err != nil {
+				return false, err
+			}
+			if seen[mac] {
+				return false, ErrInvalidValue
+			}
+			seen[mac], e.MACAddress = true, mac
+		}
+		vals = append(vals,
+			fmt.Sprintf("WifiMacFilterMac%d", i), e.MACAddress,
+			fmt.Sprintf("wifihostname%d", i), xmlEscape(e.Hostname),
+		)
+	}
+
+	return c.doReqCheckOK("api/wlan/mac-filter", SimpleRequestXML(vals...))
+}
+
+// MacFilterModeSet sets the WiFi MAC filter mode ("disabled", "allow" or
+// "deny"), keeping the entries.
+func (c *Client) MacFilterModeSet(mode string) (bool, error) {
+	var m MacFilterMode
+	if err := m.UnmarshalText([]byte(mode)); err != nil {
+		return false, ErrInvalidValue
+	}
+
+	f, err := c.MacFilter()
+	if err != nil {
+		return false, err
+	}
+	f.Mode = m
+
+	return c.MacFilterSet(*f)
+}
+
+// MacFilterAdd adds a host to the WiFi MAC filter, or updates its hostname
+// when already present.
+func (c *Client) MacFilterAdd(mac, hostname string) (bool, error) {
+	mac, err := ParseMAC(mac)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := c.MacFilter()
+	if err != nil {
+		return false, err
+	}
+
+	if i := f.index(mac); i != -1 {
+		f.Entries[i].Hostname = hostname
+	} else {
+		if len(f.Entries) >= MacFilterMaxEntries {
+			return false, ErrMacFilterFull
+		}
+		f.Entries = append(f.Entries, MacFilterEntry{MACAddress: mac, Hostname: hostname})
+	}
+
+	return c.MacFilterSet(*f)
+}
+
+// MacFilterRemove removes a host from the WiFi MAC filter.
+func (c *Client) MacFilterRemove(mac string) (bool, error) {
+	mac, err := ParseMAC(mac)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := c.MacFilter()
+	if err != nil {
+		return false, err
+	}
+
+	i := f.index(mac)
+	if i == -1 {
+		return false, ErrMacFilterNotFound
+	}
+	f.Entries = append(f.Entries[:i], f.Entries[i+1:]...)
+
+	return c.MacFilterSet(*f)
+}
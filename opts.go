@@ -58,8 +58,20 @@ func NoSessionStart(c *Client) error {
 	return nil
 }
 
+// Redact is an option specifying the redactor used to mask sensitive data in
+// the HTTP requests and responses written by the Log option. By default,
+// DefaultRedactor is used; a nil redactor disables redaction, which is only
+// useful for debugging.
+func Redact(r *Redactor) Option {
+	return func(c *Client) error {
+		c.redactor = r
+		return nil
+	}
+}
+
 // httpLogger handles logging http requests and responses.
 type httpLogger struct {
+	client                    *Client
 	transport                 http.RoundTripper
 	requestLogf, responseLogf func(string, ...interface{})
 }
@@ -83,6 +95,11 @@ func (hl *httpLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
+	// mask sensitive data
+	if r := hl.client.redactor; r != nil {
+		reqBody, resBody = r.Dump(reqBody), r.Dump(resBody)
+	}
+
 	hl.requestLogf("%s", string(reqBody))
 	hl.responseLogf("%s", string(resBody))
 
@@ -90,10 +107,11 @@ func (hl *httpLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 // Log is an option that writes all HTTP request and response data to the
-// respective logger.
+// respective logger. Sensitive data is masked, see the Redact option.
 func Log(requestLogf, responseLogf func(string, ...interface{})) Option {
 	return func(c *Client) error {
 		hl := &httpLogger{
+			client:       c,
 			requestLogf:  requestLogf,
 			responseLogf: responseLogf,
 		}
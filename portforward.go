@@ -0,0 +1,252 @@
+package hilink
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrPortForwardNotFound is the port forward not found error.
+	ErrPortForwardNotFound = errors.New("port forward not found")
+
+	// ErrPortForwardOverlap is the overlapping port forwards error.
+	ErrPortForwardOverlap = errors.New("port forward overlaps another port forward")
+)
+
+// Protocol is an IP protocol, as used by the firewall rules.
+type Protocol int
+
+// Protocol values.
+const (
+	ProtocolBoth Protocol = 0
+	ProtocolTCP  Protocol = 6
+	ProtocolUDP  Protocol = 17
+)
+
+// String satisfies the fmt.Stringer interface.
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolBoth:
+		return "both"
+	case ProtocolTCP:
+		return "tcp"
+	case ProtocolUDP:
+		return "udp"
+	}
+	return fmt.Sprintf("unknown (%d)", int(p))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (p Protocol) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (p *Protocol) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for _, v := range []Protocol{ProtocolBoth, ProtocolTCP, ProtocolUDP} {
+		if s == v.String() || s == strconv.Itoa(int(v)) {
+			*p = v
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid protocol %q", s)
+}
+
+// overlaps determines if protocols p and o have a protocol in common.
+func (p Protocol) overlaps(o Protocol) bool {
+	return p == o || p == ProtocolBoth || o == ProtocolBoth
+}
+
+// validPort determines if port is a valid port number.
+func validPort(port uint) bool {
+	return port >= 1 && port <= 65535
+}
+
+// PortForward is a port forwarding rule (virtual server), forwarding the WAN
+// ports WanPort to WanEndPort to the LAN host LanIP, starting at LanPort. A
+// zero WanEndPort forwards a single port, and a zero LanEndPort maps the WAN
+// port range onto the same number of LAN ports. RemoteIP restricts the rule
+// to a remote host, when not empty.
+type PortForward struct {
+	Name       string
+	Enabled    bool
+	Protocol   Protocol
+	RemoteIP   string
+	WanPort    uint
+	WanEndPort uint
+	LanIP      string
+	LanPort    uint
+	LanEndPort uint
+}
+
+// wanEnd returns the last WAN port of the rule.
+func (f *PortForward) wanEnd() uint {
+	if f.WanEndPort == 0 {
+		return f.WanPort
+	}
+	return f.WanEndPort
+}
+
+// lanEnd returns the last LAN port of the rule.
+func (f *PortForward) lanEnd() uint {
+	if f.LanEndPort == 0 {
+		return f.LanPort + f.wanEnd() - f.WanPort
+	}
+	return f.LanEndPort
+}
+
+// validate checks the rule is valid.
+func (f *PortForward) validate() error {
+	if f.Name == "" || !validPort(f.WanPort) || !validPort(f.LanPort) ||
+		f.wanEnd() < f.WanPort || f.lanEnd() < f.LanPort || !validPort(f.lanEnd()) {
+		return ErrInvalidValue
+	}
+	if f.RemoteIP != "" {
+		if _, err := parseIPv4(f.RemoteIP); err != nil {
+			return err
+		}
+	}
+	switch f.Protocol {
+	case ProtocolBoth, ProtocolTCP, ProtocolUDP:
+	default:
+		return ErrInvalidValue
+	}
+	_, err := parseIPv4(f.LanIP)
+	return err
+}
+
+// overlaps determines if the WAN ports of rules f and o overlap.
+func (f *PortForward) overlaps(o *PortForward) bool {
+	return f.Protocol.overlaps(o.Protocol) && f.WanPort <= o.wanEnd() && o.WanPort <= f.wanEnd()
+}
+
+// PortForwards retrieves the port forwarding rules.
+func (c *Client) PortForwards() ([]PortForward, error) {
+	d, err := c.Do("api/security/virtual-servers", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []PortForward{}
+	if l, ok := d["Servers"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["Server"]) {
+			f := PortForward{
+				Name:     xmlString(m, "VirtualServerIPName"),
+				Enabled:  xmlString(m, "VirtualServerStatus") == "1",
+				Protocol: Protocol(xmlInt(m, "VirtualServerProtocol")),
+				RemoteIP: xmlString(m, "VirtualServerRemoteIP"),
+				WanPort:  uint(xmlInt(m, "VirtualServerWanPort")),
+				LanIP:    xmlString(m, "VirtualServerIPAddress"),
+				LanPort:  uint(xmlInt(m, "VirtualServerLanPort")),
+			}
+			if end := uint(xmlInt(m, "VirtualServerWanEndPort")); end != f.WanPort {
+				f.WanEndPort = end
+			}
+			if end := uint(xmlInt(m, "VirtualServerLanEndPort")); end != f.lanEnd() {
+				f.LanEndPort = end
+			}
+			rules = append(rules, f)
+		}
+	}
+
+	return rules, nil
+}
+
+// PortForwardsSet replaces the port forwarding rules. The rules are validated,
+// and rules with overlapping WAN ports for the same protocol are rejected.
+func (c *Client) PortForwardsSet(rules []PortForward) (bool, error) {
+	for i := range rules {
+		if err := validatePortForward(rules, i); err != nil {
+			return false, err
+		}
+	}
+	return c.portForwardsSet(rules)
+}
+
+// validatePortForward validates rule i of rules, and checks it does not
+// overlap the other rules.
+func validatePortForward(rules []PortForward, i int) error {
+	f := &rules[i]
+	if err := f.validate(); err != nil {
+		return err
+	}
+	for j := range rules {
+		if j != i && f.overlaps(&rules[j]) {
+			return ErrPortForwardOverlap
+		}
+	}
+	return nil
+}
+
+// portForwardsSet writes the port forwarding rules to the device, without
+// validating them.
+func (c *Client) portForwardsSet(rules []PortForward) (bool, error) {
+	var servers string
+	for i := range rules {
+		f := &rules[i]
+
+		// note: the order is important!
+		servers += "    <Server>\n" + xmlPairsString("      ",
+			"VirtualServerIPName", xmlEscape(f.Name),
+			"VirtualServerStatus", boolToString(f.Enabled),
+			"VirtualServerRemoteIP", f.RemoteIP,
+			"VirtualServerWanPort", fmt.Sprintf("%d", f.WanPort),
+			"VirtualServerWanEndPort", fmt.Sprintf("%d", f.wanEnd()),
+			"VirtualServerLanPort", fmt.Sprintf("%d", f.LanPort),
+			"VirtualServerLanEndPort", fmt.Sprintf("%d", f.lanEnd()),
+			"VirtualServerIPAddress", f.LanIP,
+			"VirtualServerProtocol", fmt.Sprintf("%d", f.Protocol),
+		) + "    </Server>\n"
+	}
+
+	return c.doReqCheckOK("api/security/virtual-servers", SimpleRequestXML(
+		"Servers", "\n"+servers+"  ",
+	))
+}
+
+// PortForwardAdd adds a port forwarding rule. Only the new rule is validated,
+// the existing rules are written back as retrieved from the device.
+func (c *Client) PortForwardAdd(f PortForward) (bool, error) {
+	rules, err := c.PortForwards()
+	if err != nil {
+		return false, err
+	}
+	rules = append(rules, f)
+	if err = validatePortForward(rules, len(rules)-1); err != nil {
+		return false, err
+	}
+	return c.portForwardsSet(rules)
+}
+
+// PortForwardUpdate replaces the port forwarding rule at index. Only the new
+// rule is validated, the other rules are written back as retrieved from the
+// device.
+func (c *Client) PortForwardUpdate(index uint, f PortForward) (bool, error) {
+	rules, err := c.PortForwards()
+	if err != nil {
+		return false, err
+	}
+	if index >= uint(len(rules)) {
+		return false, ErrPortForwardNotFound
+	}
+	rules[index] = f
+	if err = validatePortForward(rules, int(index)); err != nil {
+		return false, err
+	}
+	return c.portForwardsSet(rules)
+}
+
+// PortForwardDelete removes the port forwarding rule at index.
+func (c *Client) PortForwardDelete(index uint) (bool, error) {
+	rules, err := c.PortForwards()
+	if err != nil {
+		return false, err
+	}
+	if index >= uint(len(rules)) {
+		return false, ErrPortForwardNotFound
+	}
+	return c.portForwardsSet(append(rules[:index], rules[index+1:]...))
+}
@@ -0,0 +1,323 @@
+package hilink
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrProfileNotFound is the profile not found error.
+	ErrProfileNotFound = errors.New("profile not found")
+
+	// ErrProfileReadOnly is the profile read-only error.
+	ErrProfileReadOnly = errors.New("profile is read-only")
+)
+
+// AuthMode is the authentication mode of a connection profile.
+type AuthMode int
+
+// AuthMode values.
+const (
+	AuthModeNone AuthMode = iota
+	AuthModePAP
+	AuthModeCHAP
+)
+
+// authModeNames are the names for the AuthMode values.
+var authModeNames = []string{"none", "pap", "chap"}
+
+// String satisfies the fmt.Stringer interface.
+func (m AuthMode) String() string {
+	if m >= 0 && int(m) < len(authModeNames) {
+		return authModeNames[m]
+	}
+	return fmt.Sprintf("unknown (%d)", int(m))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (m AuthMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (m *AuthMode) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for i, n := range authModeNames {
+		if s == n || s == strconv.Itoa(i) {
+			*m = AuthMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid auth mode %q", s)
+}
+
+// PDPType is the PDP (IP) type of a connection profile.
+type PDPType int
+
+// PDPType values.
+const (
+	PDPTypeIPv4 PDPType = iota
+	PDPTypeIPv6
+	PDPTypeIPv4v6
+)
+
+// pdpTypeNames are the names for the PDPType values.
+var pdpTypeNames = []string{"ipv4", "ipv6", "ipv4v6"}
+
+// String satisfies the fmt.Stringer interface.
+func (t PDPType) String() string {
+	if t >= 0 && int(t) < len(pdpTypeNames) {
+		return pdpTypeNames[t]
+	}
+	return fmt.Sprintf("unknown (%d)", int(t))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (t PDPType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (t *PDPType) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for i, n := range pdpTypeNames {
+		if s == n || s == strconv.Itoa(i) {
+			*t = PDPType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid PDP type %q", s)
+}
+
+// Profile is a connection (dialup) profile, ie, an APN.
+//
+// IPAddress, PrimaryDNS and SecondaryDNS are only used when not empty, in
+// which case the profile uses a static IP address and/or static DNS servers.
+type Profile struct {
+	Index        uint
+	Name         string
+	ApnName      string
+	Username     string
+	Password     string
+	AuthMode     AuthMode
+	PDPType      PDPType
+	DialupNumber string
+	IPAddress    string
+	PrimaryDNS   string
+	SecondaryDNS string
+	ReadOnly     bool
+	IsDefault    bool
+}
+
+// parseProfile converts a <Profile/> element into a Profile.
+func parseProfile(m map[string]interface{}, current uint) Profile {
+	p := Profile{
+		Index:        uint(xmlInt(m, "Index")),
+		Name:         xmlString(m, "Name"),
+		ApnName:      xmlString(m, "ApnName"),
+		Username:     xmlString(m, "Username"),
+		Password:     xmlString(m, "Password"),
+		AuthMode:     AuthMode(xmlInt(m, "AuthMode")),
+		PDPType:      PDPType(xmlInt(m, "iptype")),
+		DialupNumber: xmlString(m, "DialupNum"),
+		ReadOnly:     xmlString(m, "ReadOnly") == "1",
+	}
+	if xmlString(m, "IpIsStatic") == "1" {
+		p.IPAddress = xmlString(m, "IpAddress")
+	}
+	if xmlString(m, "DnsIsStatic") == "1" {
+		p.PrimaryDNS = xmlString(m, "PrimaryDns")
+		p.SecondaryDNS = xmlString(m, "SecondaryDns")
+	}
+	p.IsDefault = current != 0 && p.Index == current
+	return p
+}
+
+// profileList retrieves the connection profiles and the current (default)
+// profile index.
+func (c *Client) profileList() ([]Profile, uint, error) {
+	d, err := c.ProfileInfo()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	current := uint(xmlInt(d, "CurrentProfile"))
+
+	var profiles []Profile
+	if w, ok := d["Profiles"].(map[string]interface{}); ok {
+		for _, m := range xmlList(w["Profile"]) {
+			profiles = append(profiles, parseProfile(m, current))
+		}
+	}
+
+	return profiles, current, nil
+}
+
+// ProfileList retrieves the connection profiles.
+func (c *Client) ProfileList() ([]Profile, error) {
+	profiles, _, err := c.profileList()
+	return profiles, err
+}
+
+// ProfileByName retrieves the connection profile with the specified name.
+func (c *Client) ProfileByName(name string) (*Profile, error) {
+	profiles, err := c.ProfileList()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+	return nil, ErrProfileNotFound
+}
+
+// ProfileByIndex retrieves the connection profile with the specified index.
+func (c *Client) ProfileByIndex(index uint) (*Profile, error) {
+	profiles, err := c.ProfileList()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		if p.Index == index {
+			return &p, nil
+		}
+	}
+	return nil, ErrProfileNotFound
+}
+
+// profileXML builds the <Profile/> element for a profile modification.
+func profileXML(index string, p Profile) string {
+	dialup := p.DialupNumber
+	if dialup == "" {
+		dialup = "*99#"
+	}
+
+	// note: the order is important!
+	vals := []string{
+		"Index", index,
+		"IsValid", "1",
+		"Name", xmlEscape(p.Name),
+		"ApnIsStatic", "1",
+		"ApnName", xmlEscape(p.ApnName),
+		"DialupNum", xmlEscape(dialup),
+		"Username", xmlEscape(p.Username),
+	}
+
+	// masked passwords, as returned by some firmwares, are left unchanged
+	if !IsMasked(p.Password) {
+		vals = append(vals, "Password", xmlEscape(p.Password))
+	}
+
+	return "\n" + xmlPairsString("    ", append(vals,
+		"AuthMode", fmt.Sprintf("%d", p.AuthMode),
+		"IpIsStatic", boolToString(p.IPAddress != ""),
+		"IpAddress", p.IPAddress,
+		"DnsIsStatic", boolToString(p.PrimaryDNS != "" || p.SecondaryDNS != ""),
+		"PrimaryDns", p.PrimaryDNS,
+		"SecondaryDns", p.SecondaryDNS,
+		"ReadOnly", "0",
+		"iptype", fmt.Sprintf("%d", p.PDPType),
+	)...) + "  "
+}
+
+// doReqProfile wraps a connection profile modification request (modify
+// values: 1-create, 2-update).
+func (c *Client) doReqProfile(modify int, index string, setDefault uint, p Profile) (bool, error) {
+	return c.doReqCheckOK("api/dialup/profiles", SimpleRequestXML(
+		"Delete", "0",
+		"SetDefault", fmt.Sprintf("%d", setDefault),
+		"Modify", fmt.Sprintf("%d", modify),
+		"Profile", profileXML(index, p),
+	))
+}
+
+// ProfileCreate creates a new connection profile, making it the default
+// profile when p.IsDefault is set. The profile index is assigned by the
+// device.
+func (c *Client) ProfileCreate(p Profile) (bool, error) {
+	profiles, current, err := c.profileList()
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := c.doReqProfile(1, "", current, p)
+	if err != nil || !ok || !p.IsDefault {
+		return ok, err
+	}
+
+	// the index assigned by the device is only known once created
+	index, err := c.createdProfileIndex(profiles, p.Name)
+	if err != nil {
+		return false, err
+	}
+	return c.ProfileSetDefault(index)
+}
+
+// createdProfileIndex retrieves the index of the profile named name, that is
+// not in the profiles retrieved before its creation.
+func (c *Client) createdProfileIndex(before []Profile, name string) (uint, error) {
+	profiles, err := c.ProfileList()
+	if err != nil {
+		return 0, err
+	}
+
+	existing := make(map[uint]bool)
+	for _, e := range before {
+		existing[e.Index] = true
+	}
+	for _, e := range profiles {
+		if !existing[e.Index] && e.Name == name {
+			return e.Index, nil
+		}
+	}
+	return 0, ErrProfileNotFound
+}
+
+// ProfileUpdate modifies the connection profile with the specified index,
+// making it the default profile when p.IsDefault is set. A masked password,
+// as returned by ProfileList on some firmwares, is left unchanged.
+func (c *Client) ProfileUpdate(index uint, p Profile) (bool, error) {
+	profiles, current, err := c.profileList()
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for _, e := range profiles {
+		if e.Index == index {
+			if e.ReadOnly {
+				return false, ErrProfileReadOnly
+			}
+			found = true
+		}
+	}
+	if !found {
+		return false, ErrProfileNotFound
+	}
+
+	if p.IsDefault {
+		current = index
+	}
+
+	return c.doReqProfile(2, fmt.Sprintf("%d", index), current, p)
+}
+
+// ProfileRemove deletes the connection profile with the specified index,
+// setting newDefault as the default profile (0 when there is no profile
+// left).
+func (c *Client) ProfileRemove(index, newDefault uint) (bool, error) {
+	return c.ProfileDelete(fmt.Sprintf("%d", index), fmt.Sprintf("%d", newDefault))
+}
+
+// ProfileSetDefault sets the default connection profile.
+func (c *Client) ProfileSetDefault(index uint) (bool, error) {
+	return c.doReqCheckOK("api/dialup/profiles", SimpleRequestXML(
+		"Delete", "0",
+		"SetDefault", fmt.Sprintf("%d", index),
+		"Modify", "0",
+	))
+}
@@ -0,0 +1,284 @@
+package hilink
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ApnEntry is an APN provisioning database entry, matching SIM cards by IMSI
+// prefix (ie, MCC-MNC, optionally followed by more digits of the MSIN) and/or
+// by ICCID prefix.
+type ApnEntry struct {
+	IMSIPrefix  string   `json:"imsi,omitempty" yaml:"imsi,omitempty"`
+	ICCIDPrefix string   `json:"iccid,omitempty" yaml:"iccid,omitempty"`
+	Operator    string   `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Name        string   `json:"name,omitempty" yaml:"name,omitempty"`
+	ApnName     string   `json:"apn" yaml:"apn"`
+	Username    string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password    string   `json:"password,omitempty" yaml:"password,omitempty"`
+	AuthMode    AuthMode `json:"auth,omitempty" yaml:"auth,omitempty"`
+	PDPType     PDPType  `json:"pdp,omitempty" yaml:"pdp,omitempty"`
+}
+
+// Profile returns the connection profile for the entry. The profile is named
+// after the entry name, or the APN when the entry has no name.
+func (e ApnEntry) Profile() Profile {
+	name := e.Name
+	if name == "" {
+		name = e.ApnName
+	}
+	return Profile{
+		Name:     name,
+		ApnName:  e.ApnName,
+		Username: e.Username,
+		Password: e.Password,
+		AuthMode: e.AuthMode,
+		PDPType:  e.PDPType,
+	}
+}
+
+// hasPrefix determines if the entry has an IMSI or ICCID prefix. Entries
+// without a prefix would match any SIM card, and are ignored.
+func (e ApnEntry) hasPrefix() bool {
+	return e.IMSIPrefix != "" || e.ICCIDPrefix != ""
+}
+
+// key returns the key identifying the SIM cards matched by the entry.
+func (e ApnEntry) key() string {
+	return e.IMSIPrefix + "/" + e.ICCIDPrefix
+}
+
+// ApnDatabase is an APN provisioning database.
+type ApnDatabase struct {
+	Entries []ApnEntry `json:"entries" yaml:"entries"`
+}
+
+//go:generate go run ./internal/genapn
+
+// DefaultApnDatabase returns the bundled APN provisioning database, generated
+// from apn.yaml.
+func DefaultApnDatabase() *ApnDatabase {
+	db := &ApnDatabase{Entries: make([]ApnEntry, len(defaultApnEntries))}
+	copy(db.Entries, defaultApnEntries)
+	return db
+}
+
+// LoadApnDatabase reads an APN provisioning database in YAML or JSON format.
+// Note that prefixes need to be quoted in YAML, as they would otherwise be
+// read as numbers.
+func LoadApnDatabase(r io.Reader) (*ApnDatabase, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so this handles both
+	db := new(ApnDatabase)
+	if err = yaml.UnmarshalStrict(buf, db); err != nil {
+		return nil, err
+	}
+
+	if err = db.validate(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// validate checks that all entries have a prefix and an APN.
+func (db *ApnDatabase) validate() error {
+	for i, e := range db.Entries {
+		if e.IMSIPrefix == "" && e.ICCIDPrefix == "" {
+			return fmt.Errorf("apn database entry %d: no imsi or iccid prefix", i)
+		}
+		if e.ApnName == "" {
+			return fmt.Errorf("apn database entry %d: no apn", i)
+		}
+	}
+	return nil
+}
+
+// LoadApnDatabaseFile reads an APN provisioning database from a YAML or JSON
+// file.
+func LoadApnDatabaseFile(path string) (*ApnDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadApnDatabase(f)
+}
+
+// Merge returns a new database containing the entries of db and o, where the
+// entries of o replace the entries of db that match the same prefixes.
+// Entries without a prefix are dropped.
+func (db *ApnDatabase) Merge(o *ApnDatabase) *ApnDatabase {
+	override := make(map[string]bool)
+	for _, e := range o.Entries {
+		override[e.key()] = true
+	}
+
+	m := new(ApnDatabase)
+	for _, e := range db.Entries {
+		if e.hasPrefix() && !override[e.key()] {
+			m.Entries = append(m.Entries, e)
+		}
+	}
+	for _, e := range o.Entries {
+		if e.hasPrefix() {
+			m.Entries = append(m.Entries, e)
+		}
+	}
+
+	return m
+}
+
+// Lookup finds the entry for a SIM card. Entries matching the ICCID take
+// precedence over entries only matching the IMSI, as MVNOs share the MCC-MNC
+// of their host network. Otherwise the longest matching prefix wins.
+func (db *ApnDatabase) Lookup(imsi, iccid string) (*ApnEntry, bool) {
+	var best *ApnEntry
+	bestScore := 0
+
+	for i := range db.Entries {
+		e := &db.Entries[i]
+		if !e.hasPrefix() {
+			continue
+		}
+		if e.IMSIPrefix != "" && !strings.HasPrefix(imsi, e.IMSIPrefix) {
+			continue
+		}
+		if e.ICCIDPrefix != "" && !strings.HasPrefix(iccid, e.ICCIDPrefix) {
+			continue
+		}
+
+		score := len(e.IMSIPrefix)
+		if e.ICCIDPrefix != "" {
+			score += 100 + len(e.ICCIDPrefix)
+		}
+		if score >= bestScore {
+			best, bestScore = e, score
+		}
+	}
+
+	return best, best != nil
+}
+
+// ProvisionAction is the action taken by Provision.
+type ProvisionAction int
+
+// ProvisionAction values.
+const (
+	ProvisionNoMatch ProvisionAction = iota
+	ProvisionUnchanged
+	ProvisionSelected
+	ProvisionCreated
+)
+
+// String satisfies the fmt.Stringer interface.
+func (a ProvisionAction) String() string {
+	switch a {
+	case ProvisionNoMatch:
+		return "no match"
+	case ProvisionUnchanged:
+		return "unchanged"
+	case ProvisionSelected:
+		return "selected"
+	case ProvisionCreated:
+		return "created"
+	}
+	return fmt.Sprintf("unknown (%d)", int(a))
+}
+
+// ProvisionResult reports what was done by Provision.
+type ProvisionResult struct {
+	Action  ProvisionAction
+	IMSI    string
+	ICCID   string
+	Entry   *ApnEntry
+	Profile *Profile
+}
+
+// String satisfies the fmt.Stringer interface.
+func (r *ProvisionResult) String() string {
+	if r.Entry == nil {
+		return fmt.Sprintf("imsi %s, iccid %s: %v", r.IMSI, r.ICCID, r.Action)
+	}
+	return fmt.Sprintf("imsi %s, iccid %s: apn %s %v", r.IMSI, r.ICCID, r.Entry.ApnName, r.Action)
+}
+
+// sameApn determines if profile p uses the APN settings of profile q. Masked
+// passwords, as returned by some firmwares, are not compared.
+func sameApn(p, q Profile) bool {
+	return p.ApnName == q.ApnName &&
+		p.Username == q.Username &&
+		(p.Password == q.Password || IsMasked(p.Password) || IsMasked(q.Password)) &&
+		p.AuthMode == q.AuthMode &&
+		p.PDPType == q.PDPType
+}
+
+// Provision configures the connection profile for the inserted SIM card using
+// the APN database. The profile is only changed when the default profile
+// differs from the database entry: an existing profile with the same APN
+// settings is selected as default, otherwise a new default profile is created.
+func (c *Client) Provision(db *ApnDatabase) (*ProvisionResult, error) {
+	d, err := c.DeviceInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ProvisionResult{
+		IMSI:  xmlString(d, "Imsi"),
+		ICCID: xmlString(d, "Iccid"),
+	}
+
+	// find entry
+	e, ok := db.Lookup(res.IMSI, res.ICCID)
+	if !ok || (res.IMSI == "" && res.ICCID == "") {
+		return res, nil
+	}
+	res.Entry = e
+	want := e.Profile()
+
+	profiles, err := c.ProfileList()
+	if err != nil {
+		return nil, err
+	}
+
+	// check default, then other existing profiles
+	for i := range profiles {
+		if p := &profiles[i]; p.IsDefault && sameApn(*p, want) {
+			res.Action, res.Profile = ProvisionUnchanged, p
+			return res, nil
+		}
+	}
+	for i := range profiles {
+		if p := &profiles[i]; sameApn(*p, want) {
+			if err = CheckOK(c.ProfileSetDefault(p.Index)); err != nil {
+				return nil, err
+			}
+			p.IsDefault = true
+			res.Action, res.Profile = ProvisionSelected, p
+			return res, nil
+		}
+	}
+
+	// create profile
+	want.IsDefault = true
+	if err = CheckOK(c.ProfileCreate(want)); err != nil {
+		return nil, err
+	}
+	res.Action = ProvisionCreated
+	res.Profile, err = c.ProfileByName(want.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,174 @@
+package hilink
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// Redacted is the value replacing redacted data.
+const Redacted = "<<redacted>>"
+
+// DefaultRedactElements are the XML element (and JSON key) names redacted by
+// the default redactor: credentials, SIM PIN and PUK codes, session data,
+// subscriber and device identifiers, SMS contents, WiFi keys and the WPS AP
+// PIN.
+var DefaultRedactElements = []string{
+	"Content",
+	"CurrentPin",
+	"Iccid",
+	"Imei",
+	"Imsi",
+	"Msisdn",
+	"NewPin",
+	"Password",
+	"Phone",
+	"PukCode",
+	"SerialNumber",
+	"SesInfo",
+	"TokInfo",
+	"Username",
+	"WifiWpapsk",
+	"WifiWepKey1",
+	"WifiWepKey2",
+	"WifiWepKey3",
+	"WifiWepKey4",
+	"encpubkeyn",
+	"wpsappin",
+}
+
+// DefaultRedactHeaders are the HTTP header names redacted by the default
+// redactor.
+var DefaultRedactHeaders = []string{
+	"Cookie",
+	"Set-Cookie",
+	TokenHeader,
+	TokenHeader + "one",
+	TokenHeader + "two",
+}
+
+// Redactor masks sensitive data in HTTP dumps, XMLData and snapshots.
+// Element and header names are matched case-insensitively.
+type Redactor struct {
+	elements map[string]bool
+	headers  map[string]bool
+}
+
+// NewRedactor creates a redactor masking the specified XML elements (and JSON
+// keys) and HTTP headers.
+func NewRedactor(elements, headers []string) *Redactor {
+	r := &Redactor{
+		elements: make(map[string]bool),
+		headers:  make(map[string]bool),
+	}
+	for _, e := range elements {
+		r.elements[strings.ToLower(e)] = true
+	}
+	for _, h := range headers {
+		r.headers[strings.ToLower(h)] = true
+	}
+	return r
+}
+
+// DefaultRedactor creates a redactor using DefaultRedactElements and
+// DefaultRedactHeaders.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactElements, DefaultRedactHeaders)
+}
+
+var (
+	// xmlLeafRE matches XML elements containing only text.
+	xmlLeafRE = regexp.MustCompile(`<([A-Za-z0-9_]+)>([^<]*)</([A-Za-z0-9_]+)>`)
+
+	// jsonStringRE matches JSON object members with a string value.
+	jsonStringRE = regexp.MustCompile(`"([A-Za-z0-9_]+)"(\s*:\s*)"((?:[^"\\]|\\.)*)"`)
+)
+
+// Body masks the redacted elements of an XML or JSON body.
+func (r *Redactor) Body(buf []byte) []byte {
+	buf = xmlLeafRE.ReplaceAllFunc(buf, func(b []byte) []byte {
+		m := xmlLeafRE.FindSubmatch(b)
+		if !bytes.Equal(m[1], m[3]) || len(m[2]) == 0 || !r.elements[strings.ToLower(string(m[1]))] {
+			return b
+		}
+		return []byte("<" + string(m[1]) + ">" + Redacted + "</" + string(m[1]) + ">")
+	})
+	return jsonStringRE.ReplaceAllFunc(buf, func(b []byte) []byte {
+		m := jsonStringRE.FindSubmatch(b)
+		if len(m[3]) == 0 || !r.elements[strings.ToLower(string(m[1]))] {
+			return b
+		}
+		return []byte(`"` + string(m[1]) + `"` + string(m[2]) + `"` + Redacted + `"`)
+	})
+}
+
+// Dump masks the redacted headers and elements of an HTTP request or
+// response dump, as created by the httputil package.
+func (r *Redactor) Dump(dump []byte) []byte {
+	sep := []byte("\r\n\r\n")
+	i := bytes.Index(dump, sep)
+	if i == -1 {
+		i = len(dump)
+	}
+
+	lines := bytes.Split(dump[:i], []byte("\r\n"))
+	for j, l := range lines {
+		k := bytes.IndexByte(l, ':')
+		if j == 0 || k == -1 {
+			// request or status line
+			continue
+		}
+		if r.headers[strings.ToLower(string(bytes.TrimSpace(l[:k])))] {
+			lines[j] = append(l[:k:k], []byte(": "+Redacted)...)
+		}
+	}
+
+	res := bytes.Join(lines, []byte("\r\n"))
+	if i < len(dump) {
+		res = append(res, sep...)
+		res = append(res, r.Body(dump[i+len(sep):])...)
+	}
+	return res
+}
+
+// XMLData returns a copy of d, with the redacted elements masked.
+func (r *Redactor) XMLData(d XMLData) XMLData {
+	if d == nil {
+		return nil
+	}
+	return XMLData(r.value(map[string]interface{}(d), false).(map[string]interface{}))
+}
+
+// Snapshot returns a copy of s, with the redacted elements masked.
+func (r *Redactor) Snapshot(s *Snapshot) *Snapshot {
+	z := *s
+	z.Sections = make(map[string]XMLData, len(s.Sections))
+	for k, d := range s.Sections {
+		z.Sections[k] = r.XMLData(d)
+	}
+	return &z
+}
+
+// value returns a copy of v, masking all text values when redact is set, and
+// the text of the redacted elements otherwise.
+func (r *Redactor) value(v interface{}, redact bool) interface{} {
+	switch z := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(z))
+		for k, e := range z {
+			m[k] = r.value(e, redact || r.elements[strings.ToLower(k)])
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(z))
+		for i, e := range z {
+			l[i] = r.value(e, redact)
+		}
+		return l
+	case string:
+		if redact && z != "" {
+			return Redacted
+		}
+	}
+	return v
+}
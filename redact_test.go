@@ -0,0 +1,103 @@
+package hilink
+
+import (
+	"testing"
+)
+
+func TestRedactorBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		exp  string
+	}{
+		{
+			"xml",
+			`<request><Username>admin</Username><Password>secret</Password><AuthMode>0</AuthMode></request>`,
+			`<request><Username><<redacted>></Username><Password><<redacted>></Password><AuthMode>0</AuthMode></request>`,
+		},
+		{
+			"xml pin",
+			`<request><OperateType>0</OperateType><CurrentPin>1234</CurrentPin><NewPin>4321</NewPin><PukCode>12345678</PukCode></request>`,
+			`<request><OperateType>0</OperateType><CurrentPin><<redacted>></CurrentPin><NewPin><<redacted>></NewPin><PukCode><<redacted>></PukCode></request>`,
+		},
+		{
+			"xml wps ap pin",
+			`<response><wpsappin>12345670</wpsappin></response>`,
+			`<response><wpsappin><<redacted>></wpsappin></response>`,
+		},
+		{
+			"xml case insensitive",
+			`<response><imei>123456789012345</imei></response>`,
+			`<response><imei><<redacted>></imei></response>`,
+		},
+		{
+			"xml empty",
+			`<request><Password></Password></request>`,
+			`<request><Password></Password></request>`,
+		},
+		{
+			"xml nested",
+			`<Messages><Message><Phone>+100</Phone><Content>hello</Content><Index>1</Index></Message></Messages>`,
+			`<Messages><Message><Phone><<redacted>></Phone><Content><<redacted>></Content><Index>1</Index></Message></Messages>`,
+		},
+		{
+			"json",
+			`{"Imei": "123456789012345", "DeviceName": "E3372", "WifiWpapsk":"a \"quoted\" key"}`,
+			`{"Imei": "<<redacted>>", "DeviceName": "E3372", "WifiWpapsk":"<<redacted>>"}`,
+		},
+		{
+			"json empty",
+			`{"Password": ""}`,
+			`{"Password": ""}`,
+		},
+	}
+	r := DefaultRedactor()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if s := string(r.Body([]byte(test.body))); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+		})
+	}
+}
+
+func TestRedactorDump(t *testing.T) {
+	tests := []struct {
+		name string
+		dump string
+		exp  string
+	}{
+		{
+			"request",
+			"POST /api/user/login HTTP/1.1\r\nHost: 192.168.8.1\r\nCookie: SessionID=abc\r\n" + TokenHeader + ": def\r\n\r\n" +
+				`<request><Username>admin</Username><Password>secret</Password></request>`,
+			"POST /api/user/login HTTP/1.1\r\nHost: 192.168.8.1\r\nCookie: <<redacted>>\r\n" + TokenHeader + ": <<redacted>>\r\n\r\n" +
+				`<request><Username><<redacted>></Username><Password><<redacted>></Password></request>`,
+		},
+		{
+			"response",
+			"HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nSet-Cookie: SessionID=abc; path=/\r\n" + TokenHeader + "one: ghi\r\n\r\n" +
+				`<response><Imei>123456789012345</Imei><DeviceName>E3372</DeviceName></response>`,
+			"HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nSet-Cookie: <<redacted>>\r\n" + TokenHeader + "one: <<redacted>>\r\n\r\n" +
+				`<response><Imei><<redacted>></Imei><DeviceName>E3372</DeviceName></response>`,
+		},
+		{
+			"headers only",
+			"GET /api/device/information HTTP/1.1\r\nhost: 192.168.8.1\r\ncookie: SessionID=abc",
+			"GET /api/device/information HTTP/1.1\r\nhost: 192.168.8.1\r\ncookie: <<redacted>>",
+		},
+		{
+			"json body",
+			"HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" + `{"Msisdn": "+100", "Index": "1"}`,
+			"HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" + `{"Msisdn": "<<redacted>>", "Index": "1"}`,
+		},
+	}
+	r := DefaultRedactor()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if s := string(r.Dump([]byte(test.dump))); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package hilink
+
+import (
+	"errors"
+	"net"
+)
+
+// ErrStaticRouteNotFound is the static route not found error.
+var ErrStaticRouteNotFound = errors.New("static route not found")
+
+// StaticRoute is a static route, sending the traffic for the Destination
+// network through Gateway on Interface ("wan" or "lan"). An empty gateway
+// routes the network directly on the interface.
+type StaticRoute struct {
+	Destination string
+	Netmask     string
+	Gateway     string
+	Interface   string
+	Enabled     bool
+}
+
+// validate checks the route is valid.
+func (r *StaticRoute) validate() error {
+	dest, err := parseIPv4(r.Destination)
+	if err != nil {
+		return err
+	}
+	mask, err := parseIPv4(r.Netmask)
+	if err != nil {
+		return err
+	}
+	if ones, bits := net.IPMask(mask).Size(); ones == 0 && bits == 0 {
+		return ErrInvalidValue
+	}
+	if !dest.Equal(dest.Mask(net.IPMask(mask))) {
+		// host bits set
+		return ErrInvalidValue
+	}
+	if r.Gateway != "" {
+		if _, err = parseIPv4(r.Gateway); err != nil {
+			return err
+		}
+	}
+	if r.Interface != "wan" && r.Interface != "lan" {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+// StaticRoutes retrieves the static routes.
+func (c *Client) StaticRoutes() ([]StaticRoute, error) {
+	d, err := c.Do("api/staticroute/config", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []StaticRoute{}
+	if l, ok := d["StaticRoutes"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["StaticRoute"]) {
+			routes = append(routes, StaticRoute{
+				Destination: xmlString(m, "DestIPAddress"),
+				Netmask:     xmlString(m, "DestNetmask"),
+				Gateway:     xmlString(m, "Gateway"),
+				Interface:   xmlString(m, "Interface"),
+				Enabled:     xmlString(m, "Enable") == "1",
+			})
+		}
+	}
+
+	return routes, nil
+}
+
+// StaticRoutesSet replaces the static routes. Duplicate destinations are
+// rejected.
+func (c *Client) StaticRoutesSet(routes []StaticRoute) (bool, error) {
+	seen := make(map[string]bool)
+
+	var l string
+	for _, r := range routes {
+		if err := r.validate(); err != nil {
+			return false, err
+		}
+		k := r.Destination + "/" + r.Netmask
+		if seen[k] {
+			return false, ErrInvalidValue
+		}
+		seen[k] = true
+
+		// note: the order is important!
+		l += "    <StaticRoute>\n" + xmlPairsString("      ",
+			"DestIPAddress", r.Destination,
+			"DestNetmask", r.Netmask,
+			"Gateway", r.Gateway,
+			"Interface", r.Interface,
+			"Enable", boolToString(r.Enabled),
+		) + "    </StaticRoute>\n"
+	}
+
+	return c.doReqCheckOK("api/staticroute/config", SimpleRequestXML(
+		"StaticRoutes", "\n"+l+"  ",
+	))
+}
+
+// StaticRouteAdd adds an enabled static route.
+func (c *Client) StaticRouteAdd(destination, netmask, gateway, iface string) (bool, error) {
+	routes, err := c.StaticRoutes()
+	if err != nil {
+		return false, err
+	}
+	return c.StaticRoutesSet(append(routes, StaticRoute{
+		Destination: destination,
+		Netmask:     netmask,
+		Gateway:     gateway,
+		Interface:   iface,
+		Enabled:     true,
+	}))
+}
+
+// StaticRouteRemove removes the static route for a destination network.
+func (c *Client) StaticRouteRemove(destination, netmask string) (bool, error) {
+	routes, err := c.StaticRoutes()
+	if err != nil {
+		return false, err
+	}
+	for i, r := range routes {
+		if r.Destination == destination && r.Netmask == netmask {
+			return c.StaticRoutesSet(append(routes[:i], routes[i+1:]...))
+		}
+	}
+	return false, ErrStaticRouteNotFound
+}
+
+// WanDNS are the DNS servers assigned by the network on the WAN connection.
+// Override is set when the DHCP server hands out OverridePrimaryDNS and
+// OverrideSecondaryDNS to the LAN hosts, rather than relaying DNS requests to
+// the servers of the WAN connection, as set with LanDNSSet.
+type WanDNS struct {
+	PrimaryDNS           string
+	SecondaryDNS         string
+	Override             bool
+	OverridePrimaryDNS   string
+	OverrideSecondaryDNS string
+}
+
+// WanDNS retrieves the DNS servers used on the WAN connection.
+func (c *Client) WanDNS() (*WanDNS, error) {
+	d, err := c.StatusInfo()
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.DhcpSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	dns := &WanDNS{
+		PrimaryDNS:   xmlString(d, "PrimaryDns"),
+		SecondaryDNS: xmlString(d, "SecondaryDns"),
+		Override:     s.DNSMode == DNSModeManual,
+	}
+	if dns.Override {
+		dns.OverridePrimaryDNS, dns.OverrideSecondaryDNS = s.PrimaryDNS, s.SecondaryDNS
+	}
+
+	return dns, nil
+}
+
+// LanDNSSet sets the DNS servers handed out to the LAN clients by the DHCP
+// server: primary and secondary are handed out instead of the device itself,
+// which relays DNS requests to the servers of the WAN connection. An empty
+// primary server restores relaying. The DNS servers of the WAN connection are
+// left untouched.
+//
+// Devices without the DHCP settings API return the ErrorCodeNotSupported
+// error.
+func (c *Client) LanDNSSet(primary, secondary string) (bool, error) {
+	if primary == "" && secondary != "" {
+		return false, ErrInvalidValue
+	}
+
+	s, err := c.DhcpSettings()
+	if err != nil {
+		return false, err
+	}
+	if primary == "" {
+		s.DNSMode = DNSModeRelay
+	} else {
+		s.DNSMode, s.PrimaryDNS, s.SecondaryDNS = DNSModeManual, primary, secondary
+	}
+
+	return c.DhcpSettingsSet(*s)
+}
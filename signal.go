@@ -0,0 +1,60 @@
+package hilink
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Signal is the typed form of the signal information returned by SignalInfo.
+// Levels are in dBm (RSRP, RSSI, RSCP) or dB (RSRQ, SINR, EcIo), and are nil
+// when not reported by the device for the current network type.
+type Signal struct {
+	RSRP   *float64
+	RSRQ   *float64
+	SINR   *float64
+	RSSI   *float64
+	RSCP   *float64
+	EcIo   *float64
+	CellID string
+	PCI    string
+	Band   string
+}
+
+// parseSignalLevel parses a signal level as reported by the device (eg,
+// "-95dBm", "-8.0dB", ">=-51dBm"), returning nil when it is missing or
+// malformed.
+func parseSignalLevel(s string) *float64 {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, "<>=")
+	s = strings.TrimSuffix(s, "dBm")
+	s = strings.TrimSuffix(s, "dB")
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// ParseSignal converts the XMLData returned by SignalInfo into a Signal.
+func ParseSignal(d XMLData) *Signal {
+	return &Signal{
+		RSRP:   parseSignalLevel(xmlString(d, "rsrp")),
+		RSRQ:   parseSignalLevel(xmlString(d, "rsrq")),
+		SINR:   parseSignalLevel(xmlString(d, "sinr")),
+		RSSI:   parseSignalLevel(xmlString(d, "rssi")),
+		RSCP:   parseSignalLevel(xmlString(d, "rscp")),
+		EcIo:   parseSignalLevel(xmlString(d, "ecio")),
+		CellID: xmlString(d, "cell_id"),
+		PCI:    xmlString(d, "pci"),
+		Band:   xmlString(d, "band"),
+	}
+}
+
+// Signal retrieves the signal information as a Signal.
+func (c *Client) Signal() (*Signal, error) {
+	d, err := c.SignalInfo()
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignal(d), nil
+}
@@ -0,0 +1,193 @@
+package hilink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format.
+const SnapshotVersion = 1
+
+// Snapshot is a dump of the device state, as returned by all the XMLData
+// getters of the client. Sections are keyed by getter name (eg, DeviceInfo).
+//
+// Unsupported lists the getters not supported by the device, and Errors the
+// getters that failed for any other reason.
+type Snapshot struct {
+	Version     int                `json:"version"`
+	Time        time.Time          `json:"time"`
+	Sections    map[string]XMLData `json:"sections"`
+	Unsupported []string           `json:"unsupported,omitempty"`
+	Errors      map[string]string  `json:"errors,omitempty"`
+}
+
+// xmlDataType is the XMLData type.
+var xmlDataType = reflect.TypeOf(XMLData(nil))
+
+// Snapshot retrieves a snapshot of the device state, calling every getter of
+// the client that takes no parameter and returns XMLData. A getter failing
+// does not abort the snapshot; an error is only returned when no getter
+// succeeded.
+func (c *Client) Snapshot() (*Snapshot, error) {
+	s := &Snapshot{
+		Version:  SnapshotVersion,
+		Time:     time.Now(),
+		Sections: make(map[string]XMLData),
+		Errors:   make(map[string]string),
+	}
+
+	var first error
+	v := reflect.ValueOf(c)
+	typ := v.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if m.Type.NumIn() != 1 || m.Type.NumOut() != 2 || m.Type.Out(0) != xmlDataType {
+			continue
+		}
+
+		out := v.Method(i).Call(nil)
+		if err, _ := out[1].Interface().(error); err != nil {
+			if IsErrorCode(err, ErrorCodeNotSupported) {
+				s.Unsupported = append(s.Unsupported, m.Name)
+				continue
+			}
+			if first == nil {
+				first = err
+			}
+			s.Errors[m.Name] = err.Error()
+			continue
+		}
+		s.Sections[m.Name] = out[0].Interface().(XMLData)
+	}
+
+	if len(s.Sections) == 0 && first != nil {
+		return nil, first
+	}
+
+	return s, nil
+}
+
+// LoadSnapshot reads a snapshot in JSON format.
+func LoadSnapshot(r io.Reader) (*Snapshot, error) {
+	s := new(Snapshot)
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+	if s.Version < 1 || s.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+	return s, nil
+}
+
+// DefaultSnapshotIgnore are the sections changing on every snapshot (traffic
+// counters, signal levels, notifications, ...), which are usually ignored
+// when comparing snapshots.
+var DefaultSnapshotIgnore = []string{
+	"ConnectionInfo",
+	"CradleStatusInfo",
+	"LogInfo",
+	"MonthInfo",
+	"NotificationInfo",
+	"SignalInfo",
+	"SmsCount",
+	"StatusInfo",
+	"TrafficInfo",
+	"WlanMonthInfo",
+}
+
+// SnapshotChange is a change between two snapshots. Path is the section name,
+// followed by the element names (and list positions) leading to the value.
+type SnapshotChange struct {
+	Path    string
+	Old     string
+	New     string
+	Added   bool
+	Removed bool
+}
+
+// String satisfies the fmt.Stringer interface.
+func (c SnapshotChange) String() string {
+	switch {
+	case c.Added:
+		return fmt.Sprintf("+ %s: %q", c.Path, c.New)
+	case c.Removed:
+		return fmt.Sprintf("- %s: %q", c.Path, c.Old)
+	}
+	return fmt.Sprintf("~ %s: %q -> %q", c.Path, c.Old, c.New)
+}
+
+// DiffSnapshots compares two snapshots, returning the changed values sorted
+// by path. Values with a path starting with one of the ignore prefixes (eg,
+// DefaultSnapshotIgnore) are skipped.
+func DiffSnapshots(a, b *Snapshot, ignore ...string) []SnapshotChange {
+	x, y := a.flatten(), b.flatten()
+
+	var paths []string
+	for k := range x {
+		paths = append(paths, k)
+	}
+	for k := range y {
+		if _, ok := x[k]; !ok {
+			paths = append(paths, k)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []SnapshotChange
+loop:
+	for _, p := range paths {
+		for _, i := range ignore {
+			if p == i || strings.HasPrefix(p, i+".") || strings.HasPrefix(p, i+"[") {
+				continue loop
+			}
+		}
+
+		o, inA := x[p]
+		n, inB := y[p]
+		switch {
+		case !inA:
+			changes = append(changes, SnapshotChange{Path: p, New: n, Added: true})
+		case !inB:
+			changes = append(changes, SnapshotChange{Path: p, Old: o, Removed: true})
+		case o != n:
+			changes = append(changes, SnapshotChange{Path: p, Old: o, New: n})
+		}
+	}
+
+	return changes
+}
+
+// flatten returns the values of the snapshot keyed by path.
+func (s *Snapshot) flatten() map[string]string {
+	m := make(map[string]string)
+	for name, d := range s.Sections {
+		flattenValue(m, name, map[string]interface{}(d))
+	}
+	return m
+}
+
+// flattenValue adds the values of v to m, keyed by path.
+func flattenValue(m map[string]string, path string, v interface{}) {
+	switch z := v.(type) {
+	case map[string]interface{}:
+		if len(z) == 0 {
+			m[path] = ""
+		}
+		for k, e := range z {
+			flattenValue(m, path+"."+k, e)
+		}
+	case []interface{}:
+		for i, e := range z {
+			flattenValue(m, fmt.Sprintf("%s[%d]", path, i), e)
+		}
+	case nil:
+		m[path] = ""
+	default:
+		m[path] = fmt.Sprintf("%v", z)
+	}
+}
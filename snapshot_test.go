@@ -0,0 +1,93 @@
+package hilink
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenValue(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		exp  map[string]string
+	}{
+		{"string", "1", map[string]string{"s": "1"}},
+		{"nil", nil, map[string]string{"s": ""}},
+		{"empty element", map[string]interface{}{}, map[string]string{"s": ""}},
+		{"element", map[string]interface{}{
+			"a": "1",
+			"b": map[string]interface{}{"c": "2"},
+		}, map[string]string{"s.a": "1", "s.b.c": "2"}},
+		{"list", map[string]interface{}{
+			"l": []interface{}{"x", map[string]interface{}{"y": "z"}},
+		}, map[string]string{"s.l[0]": "x", "s.l[1].y": "z"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := make(map[string]string)
+			flattenValue(m, "s", test.v)
+			if !reflect.DeepEqual(m, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, m)
+			}
+		})
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	a := &Snapshot{Sections: map[string]XMLData{
+		"DeviceInfo": {"DeviceName": "E3372", "SoftwareVersion": "1.0"},
+		"StatusInfo": {"SignalIcon": "3"},
+		"HostList": {"Hosts": map[string]interface{}{
+			"Host": []interface{}{
+				map[string]interface{}{"MacAddress": "AA"},
+				map[string]interface{}{"MacAddress": "BB"},
+			},
+		}},
+		"Removed": {"X": "1"},
+	}}
+	b := &Snapshot{Sections: map[string]XMLData{
+		"DeviceInfo": {"DeviceName": "E3372", "SoftwareVersion": "2.0", "Imei": "123"},
+		"StatusInfo": {"SignalIcon": "5"},
+		"HostList": {"Hosts": map[string]interface{}{
+			"Host": []interface{}{
+				map[string]interface{}{"MacAddress": "AA"},
+				map[string]interface{}{"MacAddress": "CC"},
+				map[string]interface{}{"MacAddress": "DD"},
+			},
+		}},
+	}}
+
+	tests := []struct {
+		name   string
+		a, b   *Snapshot
+		ignore []string
+		exp    []SnapshotChange
+	}{
+		{"identical", a, a, nil, nil},
+		{"changes", a, b, nil, []SnapshotChange{
+			{Path: "DeviceInfo.Imei", New: "123", Added: true},
+			{Path: "DeviceInfo.SoftwareVersion", Old: "1.0", New: "2.0"},
+			{Path: "HostList.Hosts.Host[1].MacAddress", Old: "BB", New: "CC"},
+			{Path: "HostList.Hosts.Host[2].MacAddress", New: "DD", Added: true},
+			{Path: "Removed.X", Old: "1", Removed: true},
+			{Path: "StatusInfo.SignalIcon", Old: "3", New: "5"},
+		}},
+		{"ignore sections", a, b, []string{"HostList", "Removed", "StatusInfo"}, []SnapshotChange{
+			{Path: "DeviceInfo.Imei", New: "123", Added: true},
+			{Path: "DeviceInfo.SoftwareVersion", Old: "1.0", New: "2.0"},
+		}},
+		{"ignore prefix is not a name prefix", a, b, []string{"Device", "HostList.Hosts.Host", "Removed", "Status"}, []SnapshotChange{
+			{Path: "DeviceInfo.Imei", New: "123", Added: true},
+			{Path: "DeviceInfo.SoftwareVersion", Old: "1.0", New: "2.0"},
+			{Path: "StatusInfo.SignalIcon", Old: "3", New: "5"},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes := DiffSnapshots(test.a, test.b, test.ignore...)
+			if !reflect.DeepEqual(changes, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package hilink
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSpecialApplicationNotFound is the special application not found error.
+var ErrSpecialApplicationNotFound = errors.New("special application not found")
+
+// SpecialApplication is a special application (port triggering) rule: when a
+// LAN host sends traffic to TriggerPort, the ports OpenPort to OpenEndPort are
+// opened towards that host. A zero OpenEndPort opens a single port.
+type SpecialApplication struct {
+	Name            string
+	Enabled         bool
+	TriggerPort     uint
+	TriggerProtocol Protocol
+	OpenPort        uint
+	OpenEndPort     uint
+	OpenProtocol    Protocol
+
+	// openPorts are the open ports as retrieved from the device, when they
+	// could not be parsed, written back as is.
+	openPorts string
+}
+
+// openPortRange formats the open ports of the rule.
+func (a *SpecialApplication) openPortRange() string {
+	if a.openPorts != "" && a.OpenPort == 0 && a.OpenEndPort == 0 {
+		return a.openPorts
+	}
+	return formatPortRange(a.OpenPort, a.OpenEndPort)
+}
+
+// validate checks the rule is valid.
+func (a *SpecialApplication) validate() error {
+	if a.Name == "" || !validPort(a.TriggerPort) || !validPort(a.OpenPort) ||
+		!validPortRange(a.OpenPort, a.OpenEndPort) {
+		return ErrInvalidValue
+	}
+	for _, p := range []Protocol{a.TriggerProtocol, a.OpenProtocol} {
+		switch p {
+		case ProtocolBoth, ProtocolTCP, ProtocolUDP:
+		default:
+			return ErrInvalidValue
+		}
+	}
+	return nil
+}
+
+// parsePortRange parses a port or a port range ("5000-5010").
+func parsePortRange(s string) (uint, uint, error) {
+	f := strings.SplitN(strings.TrimSpace(s), "-", 2)
+	start, err := strconv.ParseUint(strings.TrimSpace(f[0]), 10, 16)
+	if err != nil {
+		return 0, 0, ErrInvalidValue
+	}
+	if len(f) == 1 {
+		return uint(start), 0, nil
+	}
+	end, err := strconv.ParseUint(strings.TrimSpace(f[1]), 10, 16)
+	if err != nil {
+		return 0, 0, ErrInvalidValue
+	}
+	return uint(start), uint(end), nil
+}
+
+// formatPortRange formats a port range as used by the WebUI.
+func formatPortRange(start, end uint) string {
+	if end == 0 || end == start {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d-%d", start, end)
+}
+
+// SpecialApplications retrieves the special application (port triggering)
+// rules.
+func (c *Client) SpecialApplications() ([]SpecialApplication, error) {
+	d, err := c.Do("api/security/special-applications", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	apps := []SpecialApplication{}
+	if l, ok := d["LanPorts"].(map[string]interface{}); ok {
+		for _, m := range xmlList(l["LanPort"]) {
+			a := SpecialApplication{
+				Name:            xmlString(m, "SpecialApplicationName"),
+				Enabled:         xmlString(m, "SpecialApplicationStatus") == "1",
+				TriggerPort:     uint(xmlInt(m, "SpecialApplicationTriggerPort")),
+				TriggerProtocol: Protocol(xmlInt(m, "SpecialApplicationTriggerProtocol")),
+				OpenProtocol:    Protocol(xmlInt(m, "SpecialApplicationOpenProtocol")),
+			}
+			var err error
+			if a.OpenPort, a.OpenEndPort, err = parsePortRange(xmlString(m, "SpecialApplicationOpenPort")); err != nil {
+				a.openPorts = xmlString(m, "SpecialApplicationOpenPort")
+			}
+			apps = append(apps, a)
+		}
+	}
+
+	return apps, nil
+}
+
+// SpecialApplicationsSet replaces the special application (port triggering)
+// rules. The rules are validated.
+func (c *Client) SpecialApplicationsSet(apps []SpecialApplication) (bool, error) {
+	for i := range apps {
+		if err := apps[i].validate(); err != nil {
+			return false, err
+		}
+	}
+	return c.specialApplicationsSet(apps)
+}
+
+// specialApplicationsSet writes the special application rules to the device,
+// without validating them.
+func (c *Client) specialApplicationsSet(apps []SpecialApplication) (bool, error) {
+	var ports string
+	for _, a := range apps {
+		// note: the order is important!
+		ports += "    <LanPort>\n" + xmlPairsString("      ",
+			"SpecialApplicationStatus", boolToString(a.Enabled),
+			"SpecialApplicationName", xmlEscape(a.Name),
+			"SpecialApplicationTriggerPort", fmt.Sprintf("%d", a.TriggerPort),
+			"SpecialApplicationTriggerProtocol", fmt.Sprintf("%d", a.TriggerProtocol),
+			"SpecialApplicationOpenPort", a.openPortRange(),
+			"SpecialApplicationOpenProtocol", fmt.Sprintf("%d", a.OpenProtocol),
+		) + "    </LanPort>\n"
+	}
+
+	return c.doReqCheckOK("api/security/special-applications", SimpleRequestXML(
+		"LanPorts", "\n"+ports+"  ",
+	))
+}
+
+// SpecialApplicationAdd adds an enabled special application (port triggering)
+// rule. Protocols are "tcp", "udp" or "both", and openPorts is a port or a
+// port range ("5000-5010"). Only the new rule is validated, the existing
+// rules are written back as retrieved from the device.
+func (c *Client) SpecialApplicationAdd(name string, triggerPort uint, triggerProtocol, openPorts, openProtocol string) (bool, error) {
+	a := SpecialApplication{
+		Name:        name,
+		Enabled:     true,
+		TriggerPort: triggerPort,
+	}
+	if err := a.TriggerProtocol.UnmarshalText([]byte(triggerProtocol)); err != nil {
+		return false, ErrInvalidValue
+	}
+	if err := a.OpenProtocol.UnmarshalText([]byte(openProtocol)); err != nil {
+		return false, ErrInvalidValue
+	}
+	var err error
+	if a.OpenPort, a.OpenEndPort, err = parsePortRange(openPorts); err != nil {
+		return false, err
+	}
+	if err = a.validate(); err != nil {
+		return false, err
+	}
+
+	apps, err := c.SpecialApplications()
+	if err != nil {
+		return false, err
+	}
+	return c.specialApplicationsSet(append(apps, a))
+}
+
+// SpecialApplicationRemove removes the special application (port triggering)
+// rule at index. The other rules are written back as retrieved from the
+// device.
+func (c *Client) SpecialApplicationRemove(index uint) (bool, error) {
+	apps, err := c.SpecialApplications()
+	if err != nil {
+		return false, err
+	}
+	if index >= uint(len(apps)) {
+		return false, ErrSpecialApplicationNotFound
+	}
+	return c.specialApplicationsSet(append(apps[:index], apps[index+1:]...))
+}
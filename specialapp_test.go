@@ -0,0 +1,53 @@
+package hilink
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSpecialApplicationAdd(t *testing.T) {
+	// the device has a rule with open ports that cannot be parsed
+	const existing = `<LanPort><SpecialApplicationStatus>1</SpecialApplicationStatus><SpecialApplicationName>old</SpecialApplicationName><SpecialApplicationTriggerPort>1000</SpecialApplicationTriggerPort><SpecialApplicationTriggerProtocol>0</SpecialApplicationTriggerProtocol><SpecialApplicationOpenPort>2000,2005</SpecialApplicationOpenPort><SpecialApplicationOpenProtocol>0</SpecialApplicationOpenProtocol></LanPort>`
+
+	var posted string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == "POST" {
+			buf, _ := ioutil.ReadAll(req.Body)
+			posted = string(buf)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><response>OK</response>`)
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><response><LanPorts>`+existing+`</LanPorts></response>`)
+	}))
+	defer s.Close()
+
+	client, err := NewClient(URL(s.URL), NoSessionStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.SpecialApplicationAdd("", 3000, "tcp", "4000", "tcp"); err != ErrInvalidValue {
+		t.Errorf("expected ErrInvalidValue for an invalid rule, got: %v", err)
+	}
+	if posted != "" {
+		t.Errorf("expected invalid rule not to be posted, got: %s", posted)
+	}
+
+	if err := CheckOK(client.SpecialApplicationAdd("new", 3000, "tcp", "4000-4010", "udp")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, exp := range []string{
+		"<SpecialApplicationName>old</SpecialApplicationName>",
+		"<SpecialApplicationOpenPort>2000,2005</SpecialApplicationOpenPort>",
+		"<SpecialApplicationName>new</SpecialApplicationName>",
+		"<SpecialApplicationOpenPort>4000-4010</SpecialApplicationOpenPort>",
+	} {
+		if !strings.Contains(posted, exp) {
+			t.Errorf("expected request to contain %s, got: %s", exp, posted)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package hilink
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ConnectionStatus represents the dialup connection status reported by the
+// device (ConnectionStatus in api/monitoring/status).
+type ConnectionStatus int
+
+// ConnectionStatus values.
+//
+// see: https://github.com/Salamek/huawei-lte-api/blob/master/huawei_lte_api/enums/dialup.py
+const (
+	ConnectionProfileInvalid       ConnectionStatus = 2
+	ConnectionAccessDenied         ConnectionStatus = 7
+	ConnectionRoamingNotAllowed    ConnectionStatus = 12
+	ConnectionNoAutoConnect        ConnectionStatus = 112
+	ConnectionNoAutoConnectRoaming ConnectionStatus = 113
+	ConnectionNoReconnect          ConnectionStatus = 114
+	ConnectionNoReconnectRoaming   ConnectionStatus = 115
+	ConnectionBandwidthExceeded    ConnectionStatus = 201
+	ConnectionConnecting           ConnectionStatus = 900
+	ConnectionConnected            ConnectionStatus = 901
+	ConnectionDisconnected         ConnectionStatus = 902
+	ConnectionDisconnecting        ConnectionStatus = 903
+	ConnectionFailed               ConnectionStatus = 904
+	ConnectionSignalPoor           ConnectionStatus = 905
+)
+
+// String satisfies the fmt.Stringer interface.
+func (s ConnectionStatus) String() string {
+	switch s {
+	case 2, 3, 5, 8, 20, 21, 23, 27, 28, 29, 30, 31, 32, 33:
+		return "profile invalid"
+	case 7, 11, 14, 37:
+		return "access denied"
+	case 12, 13:
+		return "roaming not allowed"
+	case ConnectionNoAutoConnect:
+		return "no autoconnect"
+	case ConnectionNoAutoConnectRoaming:
+		return "no autoconnect (roaming)"
+	case ConnectionNoReconnect:
+		return "no reconnect"
+	case ConnectionNoReconnectRoaming:
+		return "no reconnect (roaming)"
+	case ConnectionBandwidthExceeded:
+		return "bandwidth exceeded"
+	case ConnectionConnecting:
+		return "connecting"
+	case ConnectionConnected:
+		return "connected"
+	case ConnectionDisconnected:
+		return "disconnected"
+	case ConnectionDisconnecting:
+		return "disconnecting"
+	case ConnectionFailed:
+		return "connection failed"
+	case ConnectionSignalPoor:
+		return "signal poor"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// IsConnected returns true when the status indicates an established
+// connection.
+func (s ConnectionStatus) IsConnected() bool {
+	return s == ConnectionConnected
+}
+
+// IsFailed returns true when the status indicates a failed connection
+// attempt.
+func (s ConnectionStatus) IsFailed() bool {
+	switch {
+	case s == ConnectionFailed, s == ConnectionSignalPoor, s == ConnectionBandwidthExceeded:
+		return true
+	case s > 0 && s < 100:
+		return true
+	}
+	return false
+}
+
+// SimStatus represents the SIM card status.
+type SimStatus int
+
+// SimStatus values.
+const (
+	SimInvalid        SimStatus = 0
+	SimValid          SimStatus = 1
+	SimInvalidCS      SimStatus = 2
+	SimInvalidPS      SimStatus = 3
+	SimInvalidCSAndPS SimStatus = 4
+	SimRomSim         SimStatus = 240
+	SimNotPresent     SimStatus = 255
+)
+
+// String satisfies the fmt.Stringer interface.
+func (s SimStatus) String() string {
+	switch s {
+	case SimInvalid:
+		return "invalid"
+	case SimValid:
+		return "valid"
+	case SimInvalidCS:
+		return "invalid for CS"
+	case SimInvalidPS:
+		return "invalid for PS"
+	case SimInvalidCSAndPS:
+		return "invalid for CS and PS"
+	case SimRomSim:
+		return "ROM SIM"
+	case SimNotPresent:
+		return "not present"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// IsValid returns true when the SIM card is present and valid.
+func (s SimStatus) IsValid() bool {
+	return s == SimValid
+}
+
+// ServiceStatus represents the network service status.
+type ServiceStatus int
+
+// ServiceStatus values.
+const (
+	ServiceNone               ServiceStatus = 0
+	ServiceRestricted         ServiceStatus = 1
+	ServiceAvailable          ServiceStatus = 2
+	ServiceRestrictedRegional ServiceStatus = 3
+	ServicePowerSaving        ServiceStatus = 4
+)
+
+// String satisfies the fmt.Stringer interface.
+func (s ServiceStatus) String() string {
+	switch s {
+	case ServiceNone:
+		return "no service"
+	case ServiceRestricted:
+		return "restricted"
+	case ServiceAvailable:
+		return "available"
+	case ServiceRestrictedRegional:
+		return "restricted regional"
+	case ServicePowerSaving:
+		return "power saving"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// IsAvailable returns true when the device is registered with full service.
+func (s ServiceStatus) IsAvailable() bool {
+	return s == ServiceAvailable
+}
+
+// RoamingStatus represents the roaming status.
+type RoamingStatus int
+
+// RoamingStatus values.
+const (
+	RoamingInactive RoamingStatus = 0
+	RoamingActive   RoamingStatus = 1
+)
+
+// String satisfies the fmt.Stringer interface.
+func (s RoamingStatus) String() string {
+	switch s {
+	case RoamingInactive:
+		return "home"
+	case RoamingActive:
+		return "roaming"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// IsRoaming returns true when the device is roaming.
+func (s RoamingStatus) IsRoaming() bool {
+	return s == RoamingActive
+}
+
+// NetworkType represents the current network type, using the extended
+// numbering reported as CurrentNetworkTypeEx. Legacy CurrentNetworkType values
+// are converted using NetworkTypeFromLegacy.
+type NetworkType int
+
+// NetworkType values.
+const (
+	NetworkTypeNoService      NetworkType = 0
+	NetworkTypeGSM            NetworkType = 1
+	NetworkTypeGPRS           NetworkType = 2
+	NetworkTypeEDGE           NetworkType = 3
+	NetworkTypeIS95A          NetworkType = 21
+	NetworkTypeIS95B          NetworkType = 22
+	NetworkTypeCDMA1x         NetworkType = 23
+	NetworkTypeEVDORev0       NetworkType = 24
+	NetworkTypeEVDORevA       NetworkType = 25
+	NetworkTypeEVDORevB       NetworkType = 26
+	NetworkTypeHybridCDMA1x   NetworkType = 27
+	NetworkTypeHybridEVDORev0 NetworkType = 28
+	NetworkTypeHybridEVDORevA NetworkType = 29
+	NetworkTypeHybridEVDORevB NetworkType = 30
+	NetworkTypeEHRPDRel0      NetworkType = 31
+	NetworkTypeEHRPDRelA      NetworkType = 32
+	NetworkTypeEHRPDRelB      NetworkType = 33
+	NetworkTypeWCDMA          NetworkType = 41
+	NetworkTypeHSDPA          NetworkType = 42
+	NetworkTypeHSUPA          NetworkType = 43
+	NetworkTypeHSPA           NetworkType = 44
+	NetworkTypeHSPAPlus       NetworkType = 45
+	NetworkTypeDCHSPAPlus     NetworkType = 46
+	NetworkTypeTDSCDMA        NetworkType = 61
+	NetworkTypeTDHSDPA        NetworkType = 62
+	NetworkTypeTDHSUPA        NetworkType = 63
+	NetworkTypeTDHSPA         NetworkType = 64
+	NetworkTypeTDHSPAPlus     NetworkType = 65
+	NetworkType80216E         NetworkType = 81
+	NetworkTypeLTE            NetworkType = 101
+	NetworkTypeLTECA          NetworkType = 1011
+)
+
+// networkTypeNames are the display names for the NetworkType values.
+var networkTypeNames = map[NetworkType]string{
+	NetworkTypeNoService:      "no service",
+	NetworkTypeGSM:            "GSM",
+	NetworkTypeGPRS:           "GPRS",
+	NetworkTypeEDGE:           "EDGE",
+	NetworkTypeIS95A:          "IS-95A",
+	NetworkTypeIS95B:          "IS-95B",
+	NetworkTypeCDMA1x:         "CDMA 1x",
+	NetworkTypeEVDORev0:       "EV-DO Rev.0",
+	NetworkTypeEVDORevA:       "EV-DO Rev.A",
+	NetworkTypeEVDORevB:       "EV-DO Rev.B",
+	NetworkTypeHybridCDMA1x:   "hybrid CDMA 1x",
+	NetworkTypeHybridEVDORev0: "hybrid EV-DO Rev.0",
+	NetworkTypeHybridEVDORevA: "hybrid EV-DO Rev.A",
+	NetworkTypeHybridEVDORevB: "hybrid EV-DO Rev.B",
+	NetworkTypeEHRPDRel0:      "eHRPD Rel.0",
+	NetworkTypeEHRPDRelA:      "eHRPD Rel.A",
+	NetworkTypeEHRPDRelB:      "eHRPD Rel.B",
+	NetworkTypeWCDMA:          "WCDMA",
+	NetworkTypeHSDPA:          "HSDPA",
+	NetworkTypeHSUPA:          "HSUPA",
+	NetworkTypeHSPA:           "HSPA",
+	NetworkTypeHSPAPlus:       "HSPA+",
+	NetworkTypeDCHSPAPlus:     "DC-HSPA+",
+	NetworkTypeTDSCDMA:        "TD-SCDMA",
+	NetworkTypeTDHSDPA:        "TD-HSDPA",
+	NetworkTypeTDHSUPA:        "TD-HSUPA",
+	NetworkTypeTDHSPA:         "TD-HSPA",
+	NetworkTypeTDHSPAPlus:     "TD-HSPA+",
+	NetworkType80216E:         "802.16e",
+	NetworkTypeLTE:            "LTE",
+	NetworkTypeLTECA:          "LTE+",
+}
+
+// String satisfies the fmt.Stringer interface.
+func (t NetworkType) String() string {
+	if s, ok := networkTypeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown (%d)", int(t))
+}
+
+// Generation returns the mobile generation of the network type ("2G", "3G",
+// "4G"), or an empty string when there is no service or it is unknown.
+func (t NetworkType) Generation() string {
+	switch {
+	case t == NetworkTypeNoService:
+		return ""
+	case t <= NetworkTypeEDGE, t >= NetworkTypeIS95A && t <= NetworkTypeHybridCDMA1x:
+		return "2G"
+	case t < NetworkType80216E:
+		return "3G"
+	case t == NetworkType80216E, t == NetworkTypeLTE, t == NetworkTypeLTECA:
+		return "4G"
+	}
+	return ""
+}
+
+// legacyNetworkTypes maps legacy CurrentNetworkType values to NetworkType.
+var legacyNetworkTypes = map[int]NetworkType{
+	0:  NetworkTypeNoService,
+	1:  NetworkTypeGSM,
+	2:  NetworkTypeGPRS,
+	3:  NetworkTypeEDGE,
+	4:  NetworkTypeWCDMA,
+	5:  NetworkTypeHSDPA,
+	6:  NetworkTypeHSUPA,
+	7:  NetworkTypeHSPA,
+	8:  NetworkTypeTDSCDMA,
+	9:  NetworkTypeHSPAPlus,
+	10: NetworkTypeEVDORev0,
+	11: NetworkTypeEVDORevA,
+	12: NetworkTypeEVDORevB,
+	13: NetworkTypeCDMA1x,
+	17: NetworkTypeHSPAPlus,
+	18: NetworkTypeHSPAPlus,
+	19: NetworkTypeLTE,
+}
+
+// NetworkTypeFromLegacy converts a legacy CurrentNetworkType value to a
+// NetworkType. Unknown values are passed through unchanged.
+func NetworkTypeFromLegacy(v int) NetworkType {
+	if t, ok := legacyNetworkTypes[v]; ok {
+		return t
+	}
+	return NetworkType(v)
+}
+
+// Status is the typed form of the general device status information returned
+// by StatusInfo.
+type Status struct {
+	ConnectionStatus ConnectionStatus
+	SimStatus        SimStatus
+	ServiceStatus    ServiceStatus
+	RoamingStatus    RoamingStatus
+	NetworkType      NetworkType
+	SignalIcon       int
+	MaxSignal        int
+	WanIPAddress     string
+	WanIPv6Address   string
+	PrimaryDNS       string
+	SecondaryDNS     string
+}
+
+// ParseStatus converts the XMLData returned by StatusInfo into a Status.
+// Missing or malformed values are left as zero values, except for the network
+// type, which prefers CurrentNetworkTypeEx over CurrentNetworkType.
+func ParseStatus(d XMLData) *Status {
+	s := &Status{
+		ConnectionStatus: ConnectionStatus(xmlInt(d, "ConnectionStatus")),
+		SimStatus:        SimStatus(xmlInt(d, "SimStatus")),
+		ServiceStatus:    ServiceStatus(xmlInt(d, "ServiceStatus")),
+		RoamingStatus:    RoamingStatus(xmlInt(d, "RoamingStatus")),
+		SignalIcon:       xmlInt(d, "SignalIcon"),
+		MaxSignal:        xmlInt(d, "maxsignal"),
+		WanIPAddress:     xmlString(d, "WanIPAddress"),
+		WanIPv6Address:   xmlString(d, "WanIPv6Address"),
+		PrimaryDNS:       xmlString(d, "PrimaryDns"),
+		SecondaryDNS:     xmlString(d, "SecondaryDns"),
+	}
+
+	if ex := xmlString(d, "CurrentNetworkTypeEx"); ex != "" {
+		i, _ := strconv.Atoi(ex)
+		s.NetworkType = NetworkType(i)
+	} else {
+		s.NetworkType = NetworkTypeFromLegacy(xmlInt(d, "CurrentNetworkType"))
+	}
+
+	return s
+}
+
+// IsConnected returns true when the device has an established connection.
+func (s *Status) IsConnected() bool {
+	return s.ConnectionStatus.IsConnected()
+}
+
+// IsRoaming returns true when the device is roaming.
+func (s *Status) IsRoaming() bool {
+	return s.RoamingStatus.IsRoaming()
+}
+
+// IsRegistered returns true when the SIM is valid and the device is
+// registered with a network that provides service.
+func (s *Status) IsRegistered() bool {
+	return s.SimStatus.IsValid() && s.ServiceStatus.IsAvailable() &&
+		s.NetworkType != NetworkTypeNoService
+}
+
+// Status retrieves general device status information as a Status.
+func (c *Client) Status() (*Status, error) {
+	d, err := c.StatusInfo()
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(d), nil
+}
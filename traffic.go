@@ -0,0 +1,425 @@
+package hilink
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrafficStats are the traffic statistics of the current connection and the
+// totals since the statistics were last cleared. Rates are in bytes per
+// second.
+type TrafficStats struct {
+	CurrentConnectTime  time.Duration
+	CurrentUpload       uint64
+	CurrentDownload     uint64
+	CurrentUploadRate   uint64
+	CurrentDownloadRate uint64
+	TotalUpload         uint64
+	TotalDownload       uint64
+	TotalConnectTime    time.Duration
+}
+
+// xmlSeconds returns the value of the child element named key as a duration
+// in seconds.
+func xmlSeconds(d map[string]interface{}, key string) time.Duration {
+	return time.Duration(xmlUint64(d, key)) * time.Second
+}
+
+// TrafficStats retrieves the traffic statistics.
+func (c *Client) TrafficStats() (*TrafficStats, error) {
+	d, err := c.TrafficInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TrafficStats{
+		CurrentConnectTime:  xmlSeconds(d, "CurrentConnectTime"),
+		CurrentUpload:       xmlUint64(d, "CurrentUpload"),
+		CurrentDownload:     xmlUint64(d, "CurrentDownload"),
+		CurrentUploadRate:   xmlUint64(d, "CurrentUploadRate"),
+		CurrentDownloadRate: xmlUint64(d, "CurrentDownloadRate"),
+		TotalUpload:         xmlUint64(d, "TotalUpload"),
+		TotalDownload:       xmlUint64(d, "TotalDownload"),
+		TotalConnectTime:    xmlSeconds(d, "TotalConnectTime"),
+	}, nil
+}
+
+// MonthStats are the traffic statistics of the current billing cycle.
+type MonthStats struct {
+	Upload    uint64
+	Download  uint64
+	Duration  time.Duration
+	LastClear time.Time
+}
+
+// Total returns the total traffic of the billing cycle.
+func (s *MonthStats) Total() uint64 {
+	return s.Upload + s.Download
+}
+
+// MonthStats retrieves the traffic statistics of the current billing cycle.
+func (c *Client) MonthStats() (*MonthStats, error) {
+	d, err := c.MonthInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &MonthStats{
+		Upload:   xmlUint64(d, "CurrentMonthUpload"),
+		Download: xmlUint64(d, "CurrentMonthDownload"),
+		Duration: xmlSeconds(d, "MonthDuration"),
+	}
+	s.LastClear, _ = time.ParseInLocation("2006-1-2", xmlString(d, "MonthLastClearTime"), time.Local)
+
+	return s, nil
+}
+
+// DataPlan is the data plan configuration used by the device for the monthly
+// statistics. StartDay is the first day of the billing cycle, DataLimit the
+// data plan size in bytes, and Threshold the percentage of the data limit at
+// which the device warns the user.
+type DataPlan struct {
+	Enabled   bool
+	StartDay  int
+	DataLimit uint64
+	Threshold int
+}
+
+// data limit units as used by the WebUI.
+const (
+	megabyte = 1024 * 1024
+	gigabyte = 1024 * megabyte
+)
+
+// parseDataLimit parses a WebUI data limit (ie, "500MB" or "2GB") into bytes.
+func parseDataLimit(s string) uint64 {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := uint64(1)
+	switch {
+	case strings.HasSuffix(s, "GB"):
+		unit, s = gigabyte, strings.TrimSuffix(s, "GB")
+	case strings.HasSuffix(s, "MB"):
+		unit, s = megabyte, strings.TrimSuffix(s, "MB")
+	}
+	i, _ := strconv.ParseUint(s, 10, 64)
+	return i * unit
+}
+
+// formatDataLimit formats a data limit in bytes as used by the WebUI.
+func formatDataLimit(b uint64) string {
+	if b != 0 && b%gigabyte == 0 {
+		return fmt.Sprintf("%dGB", b/gigabyte)
+	}
+	return fmt.Sprintf("%dMB", b/megabyte)
+}
+
+// DataPlan retrieves the data plan configuration.
+func (c *Client) DataPlan() (*DataPlan, error) {
+	d, err := c.Do("api/monitoring/start_date", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataPlan{
+		Enabled:   xmlString(d, "SetMonthData") == "1",
+		StartDay:  xmlInt(d, "StartDay"),
+		DataLimit: parseDataLimit(xmlString(d, "DataLimit")),
+		Threshold: xmlInt(d, "MonthThreshold"),
+	}, nil
+}
+
+// DataPlanSet sets the data plan configuration. The data limit is rounded
+// down to whole megabytes.
+func (c *Client) DataPlanSet(p DataPlan) (bool, error) {
+	if p.StartDay < 1 || p.StartDay > 31 || p.Threshold < 0 || p.Threshold > 100 {
+		return false, ErrInvalidValue
+	}
+
+	return c.doReqCheckOK("api/monitoring/start_date", SimpleRequestXML(
+		"StartDay", fmt.Sprintf("%d", p.StartDay),
+		"DataLimit", formatDataLimit(p.DataLimit),
+		"MonthThreshold", fmt.Sprintf("%d", p.Threshold),
+		"SetMonthData", boolToString(p.Enabled),
+	))
+}
+
+// CycleStart returns the start of the billing cycle containing t, for a
+// billing cycle starting on startDay of the month. When a month has fewer days
+// than startDay, the cycle starts on the last day of that month.
+func CycleStart(t time.Time, startDay int) time.Time {
+	start := func(y int, m time.Month) time.Time {
+		day := startDay
+		if last := time.Date(y, m+1, 0, 0, 0, 0, 0, t.Location()).Day(); day > last {
+			day = last
+		}
+		return time.Date(y, m, day, 0, 0, 0, 0, t.Location())
+	}
+
+	s := start(t.Year(), t.Month())
+	if t.Before(s) {
+		s = start(t.Year(), t.Month()-1)
+	}
+	return s
+}
+
+// QuotaEventType is the type of a QuotaEvent.
+type QuotaEventType int
+
+// QuotaEventType values.
+const (
+	QuotaWarning QuotaEventType = iota
+	QuotaExceeded
+	QuotaRestored
+	QuotaError
+)
+
+// String satisfies the fmt.Stringer interface.
+func (t QuotaEventType) String() string {
+	switch t {
+	case QuotaWarning:
+		return "warning"
+	case QuotaExceeded:
+		return "exceeded"
+	case QuotaRestored:
+		return "restored"
+	case QuotaError:
+		return "error"
+	}
+	return fmt.Sprintf("unknown (%d)", int(t))
+}
+
+// QuotaEvent is an event emitted by a QuotaGuard.
+type QuotaEvent struct {
+	Type       QuotaEventType
+	Time       time.Time
+	Used       uint64
+	Limit      uint64
+	CycleStart time.Time
+	Err        error
+}
+
+// QuotaGuardOption is an option used when creating a new QuotaGuard.
+type QuotaGuardOption func(*QuotaGuard) error
+
+// QuotaGuardInterval is a quota guard option that sets the interval between
+// usage checks.
+func QuotaGuardInterval(d time.Duration) QuotaGuardOption {
+	return func(g *QuotaGuard) error {
+		if d <= 0 {
+			return ErrInvalidValue
+		}
+		g.interval = d
+		return nil
+	}
+}
+
+// QuotaGuardStartDay is a quota guard option that sets the first day of the
+// billing cycle. By default, the start day of the device data plan is used.
+func QuotaGuardStartDay(day int) QuotaGuardOption {
+	return func(g *QuotaGuard) error {
+		if day < 1 || day > 31 {
+			return ErrInvalidValue
+		}
+		g.startDay = day
+		return nil
+	}
+}
+
+// QuotaGuardWarning is a quota guard option that sets the percentage of the
+// limit at which a warning event is emitted.
+func QuotaGuardWarning(percent int) QuotaGuardOption {
+	return func(g *QuotaGuard) error {
+		if percent < 0 || percent > 100 {
+			return ErrInvalidValue
+		}
+		g.warning = percent
+		return nil
+	}
+}
+
+// QuotaGuardEvents is a quota guard option that sets a callback for quota
+// guard events.
+func QuotaGuardEvents(f func(QuotaEvent)) QuotaGuardOption {
+	return func(g *QuotaGuard) error {
+		g.onEvent = f
+		return nil
+	}
+}
+
+// QuotaGuard enforces a data quota on a Hilink device: when the traffic of the
+// current billing cycle reaches the limit, mobile data is deactivated, and it
+// is activated again when the next billing cycle starts.
+//
+// Usage is taken from the monthly statistics of the device, so the data plan
+// start day of the device should match the billing cycle: after a rollover,
+// the quota is only enforced again once the device has reset its statistics.
+// Whether mobile data is blocked is taken from the mobile data switch of the
+// device. Mobile data deactivated by others is left deactivated, including on
+// rollover. When mobile data is already deactivated when the guard starts, it
+// is assumed to have been deactivated by a previous run: it is activated by
+// the first check when usage is under the limit, and on rollover otherwise.
+type QuotaGuard struct {
+	client   *Client
+	limit    uint64
+	interval time.Duration
+	startDay int
+	warning  int
+	onEvent  func(QuotaEvent)
+	now      func() time.Time
+
+	cycle   time.Time
+	warned  bool
+	blocked bool
+
+	// caused is set when mobile data was deactivated by the guard.
+	caused bool
+
+	// stale is set on cycle rollover until the device statistics are reset,
+	// ie, until usage drops below staleUsed, the usage at rollover.
+	stale     bool
+	staleUsed uint64
+}
+
+// NewQuotaGuard creates a new quota guard for the client, with limit the
+// maximum traffic in bytes per billing cycle.
+func NewQuotaGuard(client *Client, limit uint64, opts ...QuotaGuardOption) (*QuotaGuard, error) {
+	if limit == 0 {
+		return nil, ErrInvalidValue
+	}
+
+	g := &QuotaGuard{
+		client:   client,
+		limit:    limit,
+		interval: time.Minute,
+		warning:  90,
+		now:      time.Now,
+	}
+
+	for _, o := range opts {
+		if err := o(g); err != nil {
+			return nil, err
+		}
+	}
+
+	// use device start day
+	if g.startDay == 0 {
+		p, err := client.DataPlan()
+		if err != nil {
+			return nil, err
+		}
+		g.startDay = p.StartDay
+		if g.startDay < 1 || g.startDay > 31 {
+			g.startDay = 1
+		}
+	}
+
+	return g, nil
+}
+
+// emit sends an event to the event callback.
+func (g *QuotaGuard) emit(ev QuotaEvent) {
+	if g.onEvent == nil {
+		return
+	}
+	ev.Time = g.now()
+	ev.Limit = g.limit
+	ev.CycleStart = g.cycle
+	g.onEvent(ev)
+}
+
+// Blocked returns true when mobile data was deactivated, as of the last
+// check.
+func (g *QuotaGuard) Blocked() bool {
+	return g.blocked
+}
+
+// Check checks the usage once, deactivating or activating mobile data as
+// needed.
+func (g *QuotaGuard) Check() error {
+	sw, err := g.client.MobileDataSwitch()
+	if err != nil {
+		return err
+	}
+	g.blocked = xmlString(sw, "dataswitch") == "0"
+
+	// on the first check, assume a previous run deactivated mobile data
+	first := g.cycle.IsZero()
+	g.caused = g.blocked && (g.caused || first)
+
+	s, err := g.client.MonthStats()
+	if err != nil {
+		return err
+	}
+	used := s.Total()
+
+	// re-enable on cycle rollover
+	cycle := CycleStart(g.now(), g.startDay)
+	if !cycle.Equal(g.cycle) {
+		g.cycle, g.warned = cycle, false
+		if !first {
+			g.stale, g.staleUsed = true, used
+			if err := g.restore(used); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the statistics may still be those of the previous cycle
+	if g.stale {
+		if used >= g.staleUsed && s.LastClear.Before(g.cycle) {
+			return nil
+		}
+		g.stale = false
+	}
+
+	// re-enable when under the limit, ie, when started blocked or after
+	// the statistics were cleared
+	if used < g.limit {
+		if err := g.restore(used); err != nil {
+			return err
+		}
+	}
+
+	if !g.warned && g.warning > 0 && used >= g.limit/100*uint64(g.warning) {
+		g.warned = true
+		g.emit(QuotaEvent{Type: QuotaWarning, Used: used})
+	}
+
+	if !g.blocked && used >= g.limit {
+		if err := CheckOK(g.client.MobileDataDeactivate()); err != nil {
+			return err
+		}
+		g.blocked, g.caused = true, true
+		g.emit(QuotaEvent{Type: QuotaExceeded, Used: used})
+	}
+
+	return nil
+}
+
+// restore activates mobile data, when deactivated by the guard.
+func (g *QuotaGuard) restore(used uint64) error {
+	if !g.blocked || !g.caused {
+		return nil
+	}
+	if err := CheckOK(g.client.MobileDataActivate()); err != nil {
+		return err
+	}
+	g.blocked, g.caused = false, false
+	g.emit(QuotaEvent{Type: QuotaRestored, Used: used})
+	return nil
+}
+
+// Run runs the quota guard until ctx is done.
+func (g *QuotaGuard) Run(ctx context.Context) error {
+	for {
+		if err := g.Check(); err != nil {
+			g.emit(QuotaEvent{Type: QuotaError, Err: err})
+		}
+		if err := sleepContext(ctx, g.interval); err != nil {
+			return err
+		}
+	}
+}
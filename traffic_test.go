@@ -0,0 +1,189 @@
+package hilink
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// quotaDevice is a fake device serving the mobile data switch and the
+// monthly statistics.
+type quotaDevice struct {
+	dataswitch string
+	used       uint64
+	lastClear  string
+
+	sync.Mutex
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (d *quotaDevice) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.Lock()
+	defer d.Unlock()
+
+	var res string
+	switch {
+	case req.URL.Path == "/api/dialup/mobile-dataswitch" && req.Method == "POST":
+		buf, _ := ioutil.ReadAll(req.Body)
+		switch {
+		case strings.Contains(string(buf), "<dataswitch>1</dataswitch>"):
+			d.dataswitch = "1"
+		case strings.Contains(string(buf), "<dataswitch>0</dataswitch>"):
+			d.dataswitch = "0"
+		}
+		res = "OK"
+	case req.URL.Path == "/api/dialup/mobile-dataswitch":
+		res = "<dataswitch>" + d.dataswitch + "</dataswitch>"
+	case req.URL.Path == "/api/monitoring/month_statistics":
+		res = fmt.Sprintf("<CurrentMonthDownload>%d</CurrentMonthDownload><CurrentMonthUpload>0</CurrentMonthUpload><MonthDuration>0</MonthDuration><MonthLastClearTime>%s</MonthLastClearTime>", d.used, d.lastClear)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><response>%s</response>`, res)
+}
+
+// turnOff turns the mobile data switch off.
+func (d *quotaDevice) turnOff() {
+	d.Lock()
+	defer d.Unlock()
+	d.dataswitch = "0"
+}
+
+// set sets the usage and last clear date of the statistics.
+func (d *quotaDevice) set(used uint64, lastClear string) {
+	d.Lock()
+	defer d.Unlock()
+	d.used, d.lastClear = used, lastClear
+}
+
+// switchState returns the mobile data switch state.
+func (d *quotaDevice) switchState() string {
+	d.Lock()
+	defer d.Unlock()
+	return d.dataswitch
+}
+
+func TestNewQuotaGuardZeroLimit(t *testing.T) {
+	client, err := NewClient(NoSessionStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewQuotaGuard(client, 0, QuotaGuardStartDay(1)); err != ErrInvalidValue {
+		t.Errorf("expected ErrInvalidValue, got: %v", err)
+	}
+}
+
+func TestQuotaGuardCheck(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, time.March, d, 12, 0, 0, 0, time.Local)
+	}
+	nextMonth := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.Local)
+
+	// each step sets the device statistics, turns mobile data off when off
+	// is set, runs a check at now, and verifies the resulting switch state
+	// and events
+	type step struct {
+		now        time.Time
+		used       uint64
+		lastClear  string
+		off        bool
+		dataswitch string
+		events     []QuotaEventType
+	}
+	tests := []struct {
+		name       string
+		dataswitch string
+		steps      []step
+	}{
+		{"under limit", "1", []step{
+			{day(10), 50, "2020-3-1", false, "1", nil},
+		}},
+		{"warning then exceeded", "1", []step{
+			{day(10), 95, "2020-3-1", false, "1", []QuotaEventType{QuotaWarning}},
+			{day(11), 100, "2020-3-1", false, "0", []QuotaEventType{QuotaExceeded}},
+			{day(12), 120, "2020-3-1", false, "0", nil},
+		}},
+		{"rollover waits for statistics reset", "1", []step{
+			{day(10), 150, "2020-3-1", false, "0", []QuotaEventType{QuotaWarning, QuotaExceeded}},
+			{nextMonth, 150, "2020-3-1", false, "1", []QuotaEventType{QuotaRestored}},
+			{nextMonth.Add(time.Minute), 160, "2020-3-1", false, "1", nil},
+			{nextMonth.Add(2 * time.Minute), 10, "2020-3-1", false, "1", nil},
+			{nextMonth.Add(3 * time.Minute), 100, "2020-3-1", false, "0", []QuotaEventType{QuotaWarning, QuotaExceeded}},
+		}},
+		{"rollover with statistics already reset", "1", []step{
+			{day(10), 150, "2020-3-1", false, "0", []QuotaEventType{QuotaWarning, QuotaExceeded}},
+			{nextMonth, 150, "2020-4-1", false, "0", []QuotaEventType{QuotaRestored, QuotaWarning, QuotaExceeded}},
+		}},
+		{"blocked state from device", "0", []step{
+			{day(10), 150, "2020-3-1", false, "0", []QuotaEventType{QuotaWarning}},
+			{nextMonth, 150, "2020-3-1", false, "1", []QuotaEventType{QuotaRestored}},
+		}},
+		{"restart in new cycle while blocked", "0", []step{
+			{nextMonth, 10, "2020-4-1", false, "1", []QuotaEventType{QuotaRestored}},
+		}},
+		{"restart while blocked before statistics reset", "0", []step{
+			{day(10), 150, "2020-2-1", false, "0", []QuotaEventType{QuotaWarning}},
+			{day(10).Add(time.Minute), 10, "2020-3-1", false, "1", []QuotaEventType{QuotaRestored}},
+		}},
+		{"turned off by others", "1", []step{
+			{day(9), 40, "2020-3-1", false, "1", nil},
+			{day(10), 50, "2020-3-1", true, "0", nil},
+			{day(11), 150, "2020-3-1", false, "0", []QuotaEventType{QuotaWarning}},
+			{nextMonth, 150, "2020-3-1", false, "0", nil},
+			{nextMonth.Add(time.Minute), 10, "2020-4-1", false, "0", nil},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &quotaDevice{dataswitch: test.dataswitch}
+			s := httptest.NewServer(d)
+			defer s.Close()
+
+			client, err := NewClient(URL(s.URL), NoSessionStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var events []QuotaEventType
+			var times []time.Time
+			g, err := NewQuotaGuard(client, 100, QuotaGuardStartDay(1), QuotaGuardEvents(func(ev QuotaEvent) {
+				events, times = append(events, ev.Type), append(times, ev.Time)
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, st := range test.steps {
+				events, times = nil, nil
+				d.set(st.used, st.lastClear)
+				if st.off {
+					d.turnOff()
+				}
+				g.now = func() time.Time { return st.now }
+				if err := g.Check(); err != nil {
+					t.Fatalf("step %d: expected no error, got: %v", i, err)
+				}
+				if sw := d.switchState(); sw != st.dataswitch {
+					t.Errorf("step %d: expected dataswitch %s, got: %s", i, st.dataswitch, sw)
+				}
+				if g.Blocked() != (st.dataswitch == "0") {
+					t.Errorf("step %d: expected blocked %t, got: %t", i, st.dataswitch == "0", g.Blocked())
+				}
+				if fmt.Sprint(events) != fmt.Sprint(st.events) {
+					t.Errorf("step %d: expected events %v, got: %v", i, st.events, events)
+				}
+				for _, tm := range times {
+					if !tm.Equal(st.now) {
+						t.Errorf("step %d: expected event time %v, got: %v", i, st.now, tm)
+					}
+				}
+			}
+		})
+	}
+}
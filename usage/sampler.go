@@ -0,0 +1,113 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jpunie/hilink"
+)
+
+// DefaultSampleInterval is the default interval between traffic samples.
+const DefaultSampleInterval = 5 * time.Minute
+
+// Sampler periodically reads the traffic counters of a Hilink device and
+// records the traffic since the previous sample in a Store.
+//
+// The device counters are reset when the statistics are cleared and, on most
+// firmware, when the device reboots. A counter lower than the previous sample
+// is treated as a reset, in which case its whole value is recorded. Traffic
+// between the reset and the last sample before it is lost.
+type Sampler struct {
+	client   *hilink.Client
+	store    *Store
+	interval time.Duration
+	onError  func(error)
+}
+
+// SamplerOption is an option used when creating a new Sampler.
+type SamplerOption func(*Sampler) error
+
+// SamplerInterval is a sampler option that sets the interval between samples.
+func SamplerInterval(d time.Duration) SamplerOption {
+	return func(s *Sampler) error {
+		if d <= 0 {
+			return hilink.ErrInvalidValue
+		}
+		s.interval = d
+		return nil
+	}
+}
+
+// SamplerErrors is a sampler option that sets a callback for the errors
+// encountered by Run.
+func SamplerErrors(f func(error)) SamplerOption {
+	return func(s *Sampler) error {
+		s.onError = f
+		return nil
+	}
+}
+
+// NewSampler creates a new sampler for the client, recording to store.
+func NewSampler(client *hilink.Client, store *Store, opts ...SamplerOption) (*Sampler, error) {
+	s := &Sampler{
+		client:   client,
+		store:    store,
+		interval: DefaultSampleInterval,
+	}
+
+	for _, o := range opts {
+		if err := o(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// delta returns the increase of counter cur since prev.
+func delta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// Sample reads the device counters once, recording the traffic since the
+// previous sample. The first sample of a new store only saves the counters.
+func (s *Sampler) Sample() (Usage, error) {
+	st, err := s.client.TrafficStats()
+	if err != nil {
+		return Usage{}, err
+	}
+	cur := Usage{Upload: st.TotalUpload, Download: st.TotalDownload}
+
+	prev, ok, err := s.store.lastCounters()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var u Usage
+	if ok {
+		u = Usage{
+			Upload:   delta(cur.Upload, prev.Upload),
+			Download: delta(cur.Download, prev.Download),
+		}
+	}
+
+	return u, s.store.addSample(time.Now(), u, cur)
+}
+
+// Run samples the device counters until ctx is done.
+func (s *Sampler) Run(ctx context.Context) error {
+	for {
+		if _, err := s.Sample(); err != nil && s.onError != nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.interval):
+		}
+	}
+}
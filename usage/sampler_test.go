@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jpunie/hilink"
+)
+
+func TestDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		cur, prev uint64
+		exp       uint64
+	}{
+		{"increase", 150, 100, 50},
+		{"unchanged", 100, 100, 0},
+		{"reset", 30, 100, 30},
+		{"reset to zero", 0, 100, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if d := delta(test.cur, test.prev); d != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, d)
+			}
+		})
+	}
+}
+
+// counterDevice is a fake device serving the traffic statistics.
+type counterDevice struct {
+	counters Usage
+
+	sync.Mutex
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (d *counterDevice) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.Lock()
+	defer d.Unlock()
+
+	if req.URL.Path != "/api/monitoring/traffic-statistics" {
+		http.NotFound(w, req)
+		return
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><response><TotalUpload>%d</TotalUpload><TotalDownload>%d</TotalDownload></response>`, d.counters.Upload, d.counters.Download)
+}
+
+// set sets the device counters.
+func (d *counterDevice) set(u Usage) {
+	d.Lock()
+	defer d.Unlock()
+	d.counters = u
+}
+
+func TestSamplerSample(t *testing.T) {
+	d := new(counterDevice)
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+
+	client, err := hilink.NewClient(hilink.URL(srv.URL), hilink.NoSessionStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, done := openTestStore(t)
+	defer done()
+	sampler, err := NewSampler(client, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each step sets the device counters, and verifies the recorded traffic
+	steps := []struct {
+		counters Usage
+		exp      Usage
+	}{
+		{Usage{Upload: 100, Download: 1000}, Usage{}},
+		{Usage{Upload: 150, Download: 1500}, Usage{Upload: 50, Download: 500}},
+		{Usage{Upload: 150, Download: 1500}, Usage{}},
+		{Usage{Upload: 20, Download: 1600}, Usage{Upload: 20, Download: 100}},
+	}
+	var total Usage
+	start := time.Now().Add(-time.Hour)
+	for i, st := range steps {
+		d.set(st.counters)
+		u, err := sampler.Sample()
+		if err != nil {
+			t.Fatalf("step %d: expected no error, got: %v", i, err)
+		}
+		if u != st.exp {
+			t.Errorf("step %d: expected %v, got: %v", i, st.exp, u)
+		}
+		total.add(st.exp)
+	}
+
+	u, err := s.UsageBetween(start, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != total {
+		t.Errorf("expected recorded usage %v, got: %v", total, u)
+	}
+}
@@ -0,0 +1,215 @@
+// Package usage provides a persistent traffic history store for Hilink
+// devices.
+package usage
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucket names.
+var (
+	hourlyBucket = []byte("hourly")
+	dailyBucket  = []byte("daily")
+	stateBucket  = []byte("state")
+)
+
+// key formats for the hourly (UTC) and daily (local time) buckets.
+const (
+	hourFormat = "2006-01-02T15Z"
+	dayFormat  = "2006-01-02"
+)
+
+// Usage is an amount of traffic in bytes.
+type Usage struct {
+	Upload   uint64
+	Download uint64
+}
+
+// Total returns the total traffic.
+func (u Usage) Total() uint64 {
+	return u.Upload + u.Download
+}
+
+// add adds o to u.
+func (u *Usage) add(o Usage) {
+	u.Upload += o.Upload
+	u.Download += o.Download
+}
+
+// Record is the traffic of a period (hour, day or month) starting at Start.
+type Record struct {
+	Start time.Time
+	Usage
+}
+
+// encodeUsage encodes u as a bucket value.
+func encodeUsage(u Usage) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf, u.Upload)
+	binary.BigEndian.PutUint64(buf[8:], u.Download)
+	return buf
+}
+
+// decodeUsage decodes a bucket value.
+func decodeUsage(buf []byte) Usage {
+	if len(buf) != 16 {
+		return Usage{}
+	}
+	return Usage{
+		Upload:   binary.BigEndian.Uint64(buf),
+		Download: binary.BigEndian.Uint64(buf[8:]),
+	}
+}
+
+// Store is a traffic history store, keeping the traffic per hour and per day
+// in a local database file.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the store at path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{hourlyBucket, dailyBucket, stateBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// addTo adds u to the value of key in bucket b.
+func addTo(b *bolt.Bucket, key string, u Usage) error {
+	cur := decodeUsage(b.Get([]byte(key)))
+	cur.add(u)
+	return b.Put([]byte(key), encodeUsage(cur))
+}
+
+// addUsage adds traffic at time t to the hour and day containing t.
+func addUsage(tx *bolt.Tx, t time.Time, u Usage) error {
+	if err := addTo(tx.Bucket(hourlyBucket), t.UTC().Format(hourFormat), u); err != nil {
+		return err
+	}
+	return addTo(tx.Bucket(dailyBucket), t.Local().Format(dayFormat), u)
+}
+
+// Add adds traffic at time t to the hour and day containing t.
+func (s *Store) Add(t time.Time, u Usage) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return addUsage(tx, t, u)
+	})
+}
+
+// records retrieves the records of bucket name for the periods starting in
+// [from, to). Keys sort chronologically, so the bucket is scanned from the key
+// of the period containing from.
+func (s *Store) records(name []byte, format string, loc *time.Location, from, to time.Time) ([]Record, error) {
+	var recs []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(name).Cursor()
+		for k, v := c.Seek([]byte(from.In(loc).Format(format))); k != nil; k, v = c.Next() {
+			start, err := time.ParseInLocation(format, string(k), loc)
+			if err != nil {
+				continue
+			}
+			if !start.Before(to) {
+				break
+			}
+			if start.Before(from) {
+				continue
+			}
+			recs = append(recs, Record{Start: start, Usage: decodeUsage(v)})
+		}
+		return nil
+	})
+	return recs, err
+}
+
+// Hourly retrieves the hourly records for the hours starting in [from, to).
+func (s *Store) Hourly(from, to time.Time) ([]Record, error) {
+	return s.records(hourlyBucket, hourFormat, time.UTC, from, to)
+}
+
+// Daily retrieves the daily records for the (local) days starting in
+// [from, to).
+func (s *Store) Daily(from, to time.Time) ([]Record, error) {
+	return s.records(dailyBucket, dayFormat, time.Local, from, to)
+}
+
+// Monthly retrieves the monthly totals for the (local) months starting in
+// [from, to), aggregated from the daily records.
+func (s *Store) Monthly(from, to time.Time) ([]Record, error) {
+	days, err := s.Daily(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var recs []Record
+	for _, d := range days {
+		start := time.Date(d.Start.Year(), d.Start.Month(), 1, 0, 0, 0, 0, time.Local)
+		if len(recs) == 0 || !recs[len(recs)-1].Start.Equal(start) {
+			recs = append(recs, Record{Start: start})
+		}
+		recs[len(recs)-1].add(d.Usage)
+	}
+
+	return recs, nil
+}
+
+// UsageBetween returns the traffic of the hours starting in [from, to).
+func (s *Store) UsageBetween(from, to time.Time) (Usage, error) {
+	var u Usage
+	recs, err := s.Hourly(from, to)
+	if err != nil {
+		return u, err
+	}
+	for _, r := range recs {
+		u.add(r.Usage)
+	}
+	return u, nil
+}
+
+// lastCounters retrieves the device counters of the last sample.
+func (s *Store) lastCounters() (Usage, bool, error) {
+	var u Usage
+	var ok bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(stateBucket).Get([]byte("counters"))
+		u, ok = decodeUsage(v), v != nil
+		return nil
+	})
+	return u, ok, err
+}
+
+// addSample adds the traffic of a sample and saves the device counters in a
+// single transaction.
+func (s *Store) addSample(t time.Time, delta, counters Usage) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if delta.Total() != 0 {
+			if err := addUsage(tx, t, delta); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(stateBucket).Put([]byte("counters"), encodeUsage(counters))
+	})
+}
@@ -0,0 +1,176 @@
+package usage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a store in a temporary directory.
+func openTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(filepath.Join(dir, "usage.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return s, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// sample is a sample added to a store.
+type sample struct {
+	t time.Time
+	u Usage
+}
+
+// addSamples adds the samples to the store, using the running totals as the
+// device counters.
+func addSamples(t *testing.T, s *Store, samples []sample) {
+	var counters Usage
+	for _, x := range samples {
+		counters.add(x.u)
+		if err := s.addSample(x.t, x.u, counters); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStoreAddSample(t *testing.T) {
+	s, done := openTestStore(t)
+	defer done()
+
+	if _, ok, err := s.lastCounters(); err != nil || ok {
+		t.Fatalf("expected no counters, got: %t, %v", ok, err)
+	}
+
+	// a sample without traffic only saves the counters
+	at := time.Date(2020, time.January, 31, 22, 0, 0, 0, time.UTC)
+	if err := s.addSample(at, Usage{}, Usage{Upload: 1, Download: 2}); err != nil {
+		t.Fatal(err)
+	}
+	u, ok, err := s.lastCounters()
+	if err != nil || !ok || u != (Usage{Upload: 1, Download: 2}) {
+		t.Errorf("expected counters {1 2}, got: %v, %t, %v", u, ok, err)
+	}
+	recs, err := s.Hourly(at.Add(-time.Hour), at.Add(time.Hour))
+	if err != nil || len(recs) != 0 {
+		t.Errorf("expected no records, got: %v, %v", recs, err)
+	}
+}
+
+func TestStoreHourly(t *testing.T) {
+	s, done := openTestStore(t)
+	defer done()
+
+	hour := func(h, m, sec int) time.Time {
+		return time.Date(2020, time.January, 31, h, m, sec, 0, time.UTC)
+	}
+	addSamples(t, s, []sample{
+		{hour(22, 59, 59), Usage{Upload: 1, Download: 10}},
+		{hour(23, 0, 0), Usage{Upload: 2, Download: 20}},
+		{hour(23, 30, 0), Usage{Upload: 3, Download: 30}},
+		{hour(24, 0, 0), Usage{Upload: 4, Download: 40}},
+	})
+
+	recs, err := s.Hourly(hour(0, 0, 0), hour(48, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Record{
+		{hour(22, 0, 0), Usage{Upload: 1, Download: 10}},
+		{hour(23, 0, 0), Usage{Upload: 5, Download: 50}},
+		{hour(24, 0, 0), Usage{Upload: 4, Download: 40}},
+	}
+	if !reflect.DeepEqual(recs, exp) {
+		t.Errorf("expected %v, got: %v", exp, recs)
+	}
+}
+
+func TestStoreDailyMonthly(t *testing.T) {
+	s, done := openTestStore(t)
+	defer done()
+
+	day := func(m time.Month, d, h, min int) time.Time {
+		return time.Date(2020, m, d, h, min, 0, 0, time.Local)
+	}
+	addSamples(t, s, []sample{
+		{day(time.January, 31, 12, 0), Usage{Upload: 1, Download: 10}},
+		{day(time.January, 31, 23, 59), Usage{Upload: 2, Download: 20}},
+		{day(time.February, 1, 0, 0), Usage{Upload: 3, Download: 30}},
+		{day(time.March, 1, 8, 0), Usage{Upload: 4, Download: 40}},
+	})
+
+	from, to := day(time.January, 1, 0, 0), day(time.April, 1, 0, 0)
+	recs, err := s.Daily(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Record{
+		{day(time.January, 31, 0, 0), Usage{Upload: 3, Download: 30}},
+		{day(time.February, 1, 0, 0), Usage{Upload: 3, Download: 30}},
+		{day(time.March, 1, 0, 0), Usage{Upload: 4, Download: 40}},
+	}
+	if !reflect.DeepEqual(recs, exp) {
+		t.Errorf("expected daily %v, got: %v", exp, recs)
+	}
+
+	recs, err = s.Monthly(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = []Record{
+		{day(time.January, 1, 0, 0), Usage{Upload: 3, Download: 30}},
+		{day(time.February, 1, 0, 0), Usage{Upload: 3, Download: 30}},
+		{day(time.March, 1, 0, 0), Usage{Upload: 4, Download: 40}},
+	}
+	if !reflect.DeepEqual(recs, exp) {
+		t.Errorf("expected monthly %v, got: %v", exp, recs)
+	}
+}
+
+func TestStoreUsageBetween(t *testing.T) {
+	s, done := openTestStore(t)
+	defer done()
+
+	hour := func(h, m int) time.Time {
+		return time.Date(2020, time.January, 31, h, m, 0, 0, time.UTC)
+	}
+	addSamples(t, s, []sample{
+		{hour(22, 30), Usage{Upload: 1, Download: 10}},
+		{hour(23, 10), Usage{Upload: 2, Download: 20}},
+		{hour(23, 50), Usage{Upload: 3, Download: 30}},
+		{hour(24, 10), Usage{Upload: 4, Download: 40}},
+	})
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		exp      Usage
+	}{
+		{"all", hour(0, 0), hour(48, 0), Usage{Upload: 10, Download: 100}},
+		{"single hour", hour(23, 0), hour(24, 0), Usage{Upload: 5, Download: 50}},
+		{"end excluded", hour(22, 0), hour(23, 0), Usage{Upload: 1, Download: 10}},
+		{"hour starting before from", hour(22, 30), hour(24, 0), Usage{Upload: 5, Download: 50}},
+		{"empty", hour(12, 0), hour(22, 0), Usage{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := s.UsageBetween(test.from, test.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u != test.exp {
+				t.Errorf("expected %v, got: %v", test.exp, u)
+			}
+		})
+	}
+}
@@ -2,9 +2,15 @@ package hilink
 
 import (
 	"bytes"
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/clbanning/mxj"
 )
@@ -30,6 +36,9 @@ var (
 
 	// ErrMessageTooLong is the message too long error.
 	ErrMessageTooLong = errors.New("message too long")
+
+	// ErrNotOK is the error returned when the device does not respond with OK.
+	ErrNotOK = errors.New("response not OK")
 )
 
 // SmsBoxType represents the different inbox types available on a hilink device.
@@ -162,6 +171,28 @@ var ErrorCodeMessageMap = map[string]string{
 	"125001": "invalid token",
 }
 
+// Error is an error returned by the Hilink WebUI.
+type Error struct {
+	Code    string
+	Message string
+}
+
+// Error satisfies the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("hilink error %s: %s", e.Code, e.Message)
+}
+
+// ErrorCodeNotSupported is the error code returned by the WebUI for API paths
+// not supported by the firmware.
+const ErrorCodeNotSupported = "100002"
+
+// IsErrorCode determines if err is a Hilink WebUI error with the specified
+// code.
+func IsErrorCode(err error, code string) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == code
+}
+
 // encodeXML encodes a map to standard XML values.
 func encodeXML(v interface{}) (io.Reader, error) {
 	var err error
@@ -171,6 +202,9 @@ func encodeXML(v interface{}) (io.Reader, error) {
 	case []byte:
 		buf = x
 
+	case encryptedRequest:
+		buf = []byte(x)
+
 	case XMLData:
 		// wrap in request element
 		m := mxj.Map(map[string]interface{}{
@@ -206,13 +240,13 @@ func decodeXML(buf []byte, takeFirstEl bool) (interface{}, error) {
 		}
 
 		// grab message if not passed by the api
+		code, _ := z["code"].(string)
 		msg, _ := z["message"].(string)
 		if msg == "" {
-			c, _ := z["code"].(string)
-			msg = ErrorCodeMessageMap[c]
+			msg = ErrorCodeMessageMap[code]
 		}
 
-		return nil, fmt.Errorf("hilink error %v: %s", z["code"], msg)
+		return nil, &Error{Code: code, Message: msg}
 	}
 
 	// check there is only one element
@@ -247,3 +281,114 @@ func decodeXML(buf []byte, takeFirstEl bool) (interface{}, error) {
 
 	return t, nil
 }
+
+// xmlString returns the string value of the child element named key, or an
+// empty string when it is not present.
+func xmlString(d map[string]interface{}, key string) string {
+	s, _ := d[key].(string)
+	return s
+}
+
+// XMLString returns the string value of the child element named key of the
+// XMLData returned by a getter, or an empty string when it is not present.
+func XMLString(d XMLData, key string) string {
+	return xmlString(d, key)
+}
+
+// xmlInt returns the integer value of the child element named key, or 0 when
+// it is not present or not a valid integer.
+func xmlInt(d map[string]interface{}, key string) int {
+	i, _ := strconv.Atoi(strings.TrimSpace(xmlString(d, key)))
+	return i
+}
+
+// xmlUint64 returns the unsigned integer value of the child element named
+// key, or 0 when it is not present or not a valid integer.
+func xmlUint64(d map[string]interface{}, key string) uint64 {
+	i, _ := strconv.ParseUint(strings.TrimSpace(xmlString(d, key)), 10, 64)
+	return i
+}
+
+// sleepContext pauses for the duration d, returning early with the context's
+// error if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// CheckOK converts the result of a doReqCheckOK style call into an error.
+func CheckOK(ok bool, err error) error {
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotOK
+	}
+	return nil
+}
+
+// xmlList returns the child elements of v, which is either a single element
+// (map) or a list of elements, as a slice. Any other value (ie, an empty
+// element) results in an empty slice.
+func xmlList(v interface{}) []map[string]interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{x}
+	case []interface{}:
+		l := make([]map[string]interface{}, 0, len(x))
+		for _, e := range x {
+			if m, ok := e.(map[string]interface{}); ok {
+				l = append(l, m)
+			}
+		}
+		return l
+	}
+	return nil
+}
+
+// xmlEscape escapes s for use as an XML element value.
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// xmlAppendExtra appends the string elements of d not already present in the
+// name/value pairs vals (nor in skip) to vals, sorted by name. It is used to
+// preserve the settings not known to this package when writing back settings
+// retrieved from the device.
+func xmlAppendExtra(vals []string, d map[string]interface{}, skip ...string) []string {
+	known := make(map[string]bool)
+	for i := 0; i < len(vals); i += 2 {
+		known[vals[i]] = true
+	}
+	for _, k := range skip {
+		known[k] = true
+	}
+
+	var extra []string
+	for k, v := range d {
+		if _, ok := v.(string); ok && !known[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+
+	for _, k := range extra {
+		vals = append(vals, k, xmlEscape(xmlString(d, k)))
+	}
+	return vals
+}
+
+// IsMasked determines if a secret returned by the device is masked (ie, only
+// made of '*'), as done by some firmwares for passwords and keys.
+func IsMasked(s string) bool {
+	return s != "" && strings.Trim(s, "*") == ""
+}
@@ -0,0 +1,94 @@
+package hilink
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultPollInterval is the default interval between polls of the device
+// when waiting for a state.
+const DefaultPollInterval = 2 * time.Second
+
+// poll calls f every DefaultPollInterval until it returns true or ctx is done.
+// Errors returned by f are treated as the state not being reached yet, as the
+// device is often unreachable or busy while changing state.
+func (c *Client) poll(ctx context.Context, f func() (bool, error)) error {
+	for {
+		if ok, err := f(); err == nil && ok {
+			return nil
+		}
+		if err := sleepContext(ctx, DefaultPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitForDevice waits until the WebUI answers again and a fresh session has
+// been created on the client.
+func (c *Client) WaitForDevice(ctx context.Context) error {
+	return c.poll(ctx, func() (bool, error) {
+		if err := c.startSession(); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// WaitForRegistration waits until the SIM is ready and the device is
+// registered with a network.
+func (c *Client) WaitForRegistration(ctx context.Context) error {
+	return c.poll(ctx, func() (bool, error) {
+		s, err := c.Status()
+		if err != nil {
+			return false, err
+		}
+		return s.IsRegistered(), nil
+	})
+}
+
+// WaitForConnected waits until the device has an established connection with
+// a WAN IP address.
+func (c *Client) WaitForConnected(ctx context.Context) error {
+	return c.poll(ctx, func() (bool, error) {
+		s, err := c.Status()
+		if err != nil {
+			return false, err
+		}
+		return s.IsConnected() && (s.WanIPAddress != "" || s.WanIPv6Address != ""), nil
+	})
+}
+
+// waitDown waits for the WebUI to go down after a reboot was requested, as
+// the device keeps answering for a few seconds after accepting it. It returns
+// false when the WebUI is still answering after 30 seconds.
+func (c *Client) waitDown(ctx context.Context) (bool, error) {
+	down, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	err := c.poll(down, func() (bool, error) {
+		_, _, err := c.NewSessionAndTokenID()
+		return err != nil, nil
+	})
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return err == nil, nil
+}
+
+// WaitForRestart waits for the device to go down after a reboot was
+// requested (eg, by DeviceReboot), then waits until it is available again and
+// re-establishes the session on the client.
+func (c *Client) WaitForRestart(ctx context.Context) error {
+	if _, err := c.waitDown(ctx); err != nil {
+		return err
+	}
+	return c.WaitForDevice(ctx)
+}
+
+// RebootAndWait restarts the device, waits until it is available again, and
+// re-establishes the session on the client.
+func (c *Client) RebootAndWait(ctx context.Context) error {
+	if err := CheckOK(c.DeviceReboot()); err != nil {
+		return err
+	}
+	return c.WaitForRestart(ctx)
+}
@@ -0,0 +1,463 @@
+package hilink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultWatchdogInterval is the default interval between watchdog health
+	// checks.
+	DefaultWatchdogInterval = 30 * time.Second
+
+	// DefaultWatchdogMaxBackoff is the default maximum delay between watchdog
+	// remediation rounds.
+	DefaultWatchdogMaxBackoff = 30 * time.Minute
+)
+
+// ErrNoWanIPAddress is the no WAN IP address error.
+var ErrNoWanIPAddress = errors.New("no WAN IP address")
+
+// HealthCheck is a named connection health check. Check returns a non-nil
+// error when the check fails.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context, c *Client) error
+}
+
+// CheckWanIP is a health check that verifies the device reports a WAN IP
+// address.
+func CheckWanIP() HealthCheck {
+	return HealthCheck{
+		Name: "wan-ip",
+		Check: func(ctx context.Context, c *Client) error {
+			s, err := c.Status()
+			if err != nil {
+				return err
+			}
+			if s.WanIPAddress == "" && s.WanIPv6Address == "" {
+				return ErrNoWanIPAddress
+			}
+			return nil
+		},
+	}
+}
+
+// CheckDNS is a health check that verifies host can be resolved.
+func CheckDNS(host string) HealthCheck {
+	return HealthCheck{
+		Name: "dns " + host,
+		Check: func(ctx context.Context, c *Client) error {
+			_, err := net.DefaultResolver.LookupHost(ctx, host)
+			return err
+		},
+	}
+}
+
+// CheckTCP is a health check that verifies a TCP connection can be
+// established to addr (host:port).
+func CheckTCP(addr string) HealthCheck {
+	return HealthCheck{
+		Name: "tcp " + addr,
+		Check: func(ctx context.Context, c *Client) error {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+	}
+}
+
+// CheckHTTP is a health check that verifies a GET request to urlstr returns a
+// 2xx status code.
+func CheckHTTP(urlstr string) HealthCheck {
+	return HealthCheck{
+		Name: "http " + urlstr,
+		Check: func(ctx context.Context, c *Client) error {
+			ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+			defer cancel()
+
+			req, err := http.NewRequest("GET", urlstr, nil)
+			if err != nil {
+				return err
+			}
+			res, err := http.DefaultClient.Do(req.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode < 200 || res.StatusCode > 299 {
+				return ErrBadStatusCode
+			}
+			return nil
+		},
+	}
+}
+
+// AnyCheck combines checks into a single health check that passes when any of
+// the checks pass.
+func AnyCheck(name string, checks ...HealthCheck) HealthCheck {
+	return HealthCheck{
+		Name: name,
+		Check: func(ctx context.Context, c *Client) error {
+			var err error
+			for _, hc := range checks {
+				if err = hc.Check(ctx, c); err == nil {
+					return nil
+				}
+			}
+			return err
+		},
+	}
+}
+
+// RemediationStep is a named remediation action run by a Watchdog when the
+// health checks fail.
+//
+// Cooldown is the minimum time between two runs of the step, and Settle is the
+// time to wait after running the step before checking again.
+type RemediationStep struct {
+	Name     string
+	Action   func(ctx context.Context, c *Client) error
+	Cooldown time.Duration
+	Settle   time.Duration
+}
+
+// StepRedial is a remediation step that disconnects and reconnects the
+// device.
+func StepRedial() RemediationStep {
+	return RemediationStep{
+		Name: "redial",
+		Action: func(ctx context.Context, c *Client) error {
+			// ignore disconnect failures, as the device may not be connected
+			c.Disconnect()
+			if err := sleepContext(ctx, 2*time.Second); err != nil {
+				return err
+			}
+			return CheckOK(c.Connect())
+		},
+		Settle: 15 * time.Second,
+	}
+}
+
+// StepToggleDataSwitch is a remediation step that turns the mobile data
+// switch off and back on.
+func StepToggleDataSwitch() RemediationStep {
+	return RemediationStep{
+		Name: "toggle-data-switch",
+		Action: func(ctx context.Context, c *Client) error {
+			if err := CheckOK(c.MobileDataDeactivate()); err != nil {
+				return err
+			}
+			if err := sleepContext(ctx, 2*time.Second); err != nil {
+				return err
+			}
+			return CheckOK(c.MobileDataActivate())
+		},
+		Settle: 15 * time.Second,
+	}
+}
+
+// StepReregister is a remediation step that re-registers the device with the
+// network using automatic network selection.
+func StepReregister() RemediationStep {
+	return RemediationStep{
+		Name: "reregister",
+		Action: func(ctx context.Context, c *Client) error {
+			return CheckOK(c.NetworkRegister(0, "", ""))
+		},
+		Cooldown: 5 * time.Minute,
+		Settle:   30 * time.Second,
+	}
+}
+
+// StepReboot is a remediation step that reboots the device and waits for it
+// to come back up.
+func StepReboot() RemediationStep {
+	return RemediationStep{
+		Name: "reboot",
+		Action: func(ctx context.Context, c *Client) error {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+			defer cancel()
+			return c.RebootAndWait(ctx)
+		},
+		Cooldown: time.Hour,
+		Settle:   time.Minute,
+	}
+}
+
+// WatchdogEventType is the type of a WatchdogEvent.
+type WatchdogEventType int
+
+// WatchdogEventType values.
+const (
+	WatchdogCheckFailed WatchdogEventType = iota
+	WatchdogRecovered
+	WatchdogRemediation
+	WatchdogRemediationFailed
+	WatchdogExhausted
+)
+
+// String satisfies the fmt.Stringer interface.
+func (t WatchdogEventType) String() string {
+	switch t {
+	case WatchdogCheckFailed:
+		return "check failed"
+	case WatchdogRecovered:
+		return "recovered"
+	case WatchdogRemediation:
+		return "remediation"
+	case WatchdogRemediationFailed:
+		return "remediation failed"
+	case WatchdogExhausted:
+		return "exhausted"
+	}
+	return fmt.Sprintf("unknown (%d)", int(t))
+}
+
+// WatchdogEvent is an event emitted by a Watchdog.
+type WatchdogEvent struct {
+	Type     WatchdogEventType
+	Time     time.Time
+	Check    string
+	Step     string
+	Failures int
+	Err      error
+}
+
+// WatchdogOption is an option used when creating a new Watchdog.
+type WatchdogOption func(*Watchdog) error
+
+// WatchdogInterval is a watchdog option that sets the interval between health
+// checks.
+func WatchdogInterval(d time.Duration) WatchdogOption {
+	return func(w *Watchdog) error {
+		if d <= 0 {
+			return ErrInvalidValue
+		}
+		w.interval = d
+		return nil
+	}
+}
+
+// WatchdogMaxBackoff is a watchdog option that sets the maximum delay between
+// remediation rounds.
+func WatchdogMaxBackoff(d time.Duration) WatchdogOption {
+	return func(w *Watchdog) error {
+		if d <= 0 {
+			return ErrInvalidValue
+		}
+		w.maxBackoff = d
+		return nil
+	}
+}
+
+// WatchdogChecks is a watchdog option that sets the health checks to run. All
+// checks must pass for the connection to be considered healthy.
+func WatchdogChecks(checks ...HealthCheck) WatchdogOption {
+	return func(w *Watchdog) error {
+		w.checks = checks
+		return nil
+	}
+}
+
+// WatchdogSteps is a watchdog option that sets the remediation steps, in
+// order of escalation.
+func WatchdogSteps(steps ...RemediationStep) WatchdogOption {
+	return func(w *Watchdog) error {
+		w.steps = steps
+		return nil
+	}
+}
+
+// WatchdogClientFunc is a watchdog option that sets a func returning the
+// client to use for each round, for callers recreating their client (eg,
+// after a session loss). The client passed to NewWatchdog may then be nil.
+func WatchdogClientFunc(f func() (*Client, error)) WatchdogOption {
+	return func(w *Watchdog) error {
+		w.clientFunc = f
+		return nil
+	}
+}
+
+// WatchdogEvents is a watchdog option that sets a callback for watchdog
+// events.
+func WatchdogEvents(f func(WatchdogEvent)) WatchdogOption {
+	return func(w *Watchdog) error {
+		w.onEvent = f
+		return nil
+	}
+}
+
+// Watchdog monitors the connection of a Hilink device, and runs escalating
+// remediation steps when the health checks fail.
+//
+// Each failed round of checks runs the next remediation step that is not in
+// its cooldown. Once all steps have been tried, the watchdog starts again at
+// the first step, doubling the delay between rounds up to the maximum backoff.
+// The delay and escalation are reset when the checks pass again.
+type Watchdog struct {
+	client     *Client
+	clientFunc func() (*Client, error)
+	interval   time.Duration
+	maxBackoff time.Duration
+	checks     []HealthCheck
+	steps      []RemediationStep
+	onEvent    func(WatchdogEvent)
+
+	level    int
+	failures int
+	backoff  time.Duration
+	lastRun  map[string]time.Time
+}
+
+// NewWatchdog creates a new watchdog for the client. By default, the watchdog
+// checks for a WAN IP address, and escalates from redialing, to toggling the
+// data switch, to re-registering with the network, to rebooting the device.
+func NewWatchdog(client *Client, opts ...WatchdogOption) (*Watchdog, error) {
+	w := &Watchdog{
+		client:     client,
+		interval:   DefaultWatchdogInterval,
+		maxBackoff: DefaultWatchdogMaxBackoff,
+		checks:     []HealthCheck{CheckWanIP()},
+		steps: []RemediationStep{
+			StepRedial(),
+			StepToggleDataSwitch(),
+			StepReregister(),
+			StepReboot(),
+		},
+		lastRun: make(map[string]time.Time),
+	}
+
+	for _, o := range opts {
+		if err := o(w); err != nil {
+			return nil, err
+		}
+	}
+	if w.client == nil && w.clientFunc == nil {
+		return nil, ErrInvalidValue
+	}
+
+	return w, nil
+}
+
+// currentClient returns the client to use for the current round.
+func (w *Watchdog) currentClient() (*Client, error) {
+	if w.clientFunc != nil {
+		return w.clientFunc()
+	}
+	return w.client, nil
+}
+
+// emit sends an event to the event callback.
+func (w *Watchdog) emit(ev WatchdogEvent) {
+	if w.onEvent == nil {
+		return
+	}
+	ev.Time = time.Now()
+	ev.Failures = w.failures
+	w.onEvent(ev)
+}
+
+// Check runs the health checks once, returning the name of the first failed
+// check and its error.
+func (w *Watchdog) Check(ctx context.Context) (string, error) {
+	client, err := w.currentClient()
+	if err != nil {
+		return "client", err
+	}
+	return w.check(ctx, client)
+}
+
+// check runs the health checks once using client.
+func (w *Watchdog) check(ctx context.Context, client *Client) (string, error) {
+	for _, hc := range w.checks {
+		if err := hc.Check(ctx, client); err != nil {
+			return hc.Name, err
+		}
+	}
+	return "", nil
+}
+
+// nextStep returns the index of the next remediation step to run that is not
+// in its cooldown, or -1 when there is none left in this round.
+func (w *Watchdog) nextStep(now time.Time) int {
+	for i := w.level; i < len(w.steps); i++ {
+		s := w.steps[i]
+		if last, ok := w.lastRun[s.Name]; ok && now.Sub(last) < s.Cooldown {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// Step runs a single round of health checks and, when they fail, the next
+// remediation step. It returns the delay until the next round should be run.
+func (w *Watchdog) Step(ctx context.Context) time.Duration {
+	client, err := w.currentClient()
+	if err != nil {
+		// without a client there is nothing to remediate
+		w.emit(WatchdogEvent{Type: WatchdogCheckFailed, Check: "client", Err: err})
+		return w.interval
+	}
+
+	name, err := w.check(ctx, client)
+	if err == nil {
+		if w.failures > 0 {
+			w.failures = 0
+			w.emit(WatchdogEvent{Type: WatchdogRecovered})
+		}
+		w.level, w.backoff = 0, 0
+		return w.interval
+	}
+
+	w.failures++
+	w.emit(WatchdogEvent{Type: WatchdogCheckFailed, Check: name, Err: err})
+
+	i := w.nextStep(time.Now())
+	if i < 0 {
+		// all steps tried or cooling down, start over with backoff
+		w.level = 0
+		if w.backoff == 0 {
+			w.backoff = w.interval
+		}
+		w.backoff *= 2
+		if w.backoff > w.maxBackoff {
+			w.backoff = w.maxBackoff
+		}
+		w.emit(WatchdogEvent{Type: WatchdogExhausted, Check: name, Err: err})
+		return w.backoff
+	}
+
+	s := w.steps[i]
+	w.level = i + 1
+	w.lastRun[s.Name] = time.Now()
+	w.emit(WatchdogEvent{Type: WatchdogRemediation, Check: name, Step: s.Name})
+	if err := s.Action(ctx, client); err != nil {
+		w.emit(WatchdogEvent{Type: WatchdogRemediationFailed, Check: name, Step: s.Name, Err: err})
+	}
+
+	if s.Settle > 0 {
+		return s.Settle
+	}
+	return w.interval
+}
+
+// Run runs the watchdog until ctx is done.
+func (w *Watchdog) Run(ctx context.Context) error {
+	delay := w.interval
+	for {
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+		delay = w.Step(ctx)
+	}
+}
@@ -0,0 +1,300 @@
+package hilink
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSsidNotFound is the SSID not found error.
+	ErrSsidNotFound = errors.New("ssid not found")
+
+	// ErrWlanKeyMasked is the masked WiFi key error, returned when a key
+	// would be written back as masked by the firmware.
+	ErrWlanKeyMasked = errors.New("wlan key masked by device")
+)
+
+// WlanBand is a WiFi frequency band.
+type WlanBand int
+
+// WlanBand values.
+const (
+	WlanBand24GHz WlanBand = iota
+	WlanBand5GHz
+)
+
+// wlanBandNames are the names for the WlanBand values.
+var wlanBandNames = []string{"2.4ghz", "5ghz"}
+
+// String satisfies the fmt.Stringer interface.
+func (b WlanBand) String() string {
+	if b >= 0 && int(b) < len(wlanBandNames) {
+		return wlanBandNames[b]
+	}
+	return fmt.Sprintf("unknown (%d)", int(b))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (b WlanBand) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (b *WlanBand) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for i, n := range wlanBandNames {
+		if s == n || s == strings.TrimSuffix(n, "ghz") || s == strconv.Itoa(i) {
+			*b = WlanBand(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid wlan band %q", s)
+}
+
+// WlanAuthMode is a WiFi authentication mode, as used by the WebUI.
+type WlanAuthMode string
+
+// WlanAuthMode values.
+const (
+	WlanAuthOpen        WlanAuthMode = "OPEN"
+	WlanAuthShared      WlanAuthMode = "SHARE"
+	WlanAuthWPAPSK      WlanAuthMode = "WPA-PSK"
+	WlanAuthWPA2PSK     WlanAuthMode = "WPA2-PSK"
+	WlanAuthWPAWPA2PSK  WlanAuthMode = "WPA/WPA2-PSK"
+	WlanAuthWPA3SAE     WlanAuthMode = "WPA3-SAE"
+	WlanAuthWPA2WPA3SAE WlanAuthMode = "WPA2-PSK/WPA3-SAE"
+)
+
+// usesKey determines if the authentication mode uses a pre-shared key.
+func (m WlanAuthMode) usesKey() bool {
+	return m != WlanAuthOpen && m != WlanAuthShared && m != ""
+}
+
+// WlanSettings are the settings of a WiFi network (SSID).
+//
+// Index is the SSID index, which is always 0 on devices without multi-SSID
+// support. Channel is the radio channel (0 for automatic); it is a setting of
+// the radio, shared by all SSIDs. Band is read-only: it is the band of the
+// radio used by the SSID, as reported by the device. Encryption is the WPA
+// cipher ("AES", "TKIP" or "MIX"), and Key the pre-shared key.
+type WlanSettings struct {
+	Index      uint
+	Enabled    bool
+	SSID       string
+	Hidden     bool
+	Channel    int
+	Band       WlanBand
+	MaxClients int
+	AuthMode   WlanAuthMode
+	Encryption string
+	Key        string
+}
+
+// validate checks the settings are valid.
+func (s *WlanSettings) validate() error {
+	switch {
+	case len(s.SSID) < 1 || len(s.SSID) > 32,
+		s.Channel < 0,
+		s.MaxClients < 0,
+		s.AuthMode.usesKey() && (len(s.Key) < 8 || len(s.Key) > 64):
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+// wlanState is the WLAN configuration retrieved from the device, kept for
+// writing back modified settings.
+type wlanState struct {
+	basic    XMLData
+	security XMLData
+	ssids    []map[string]interface{}
+	settings []WlanSettings
+}
+
+// multi determines if the device uses the multi-SSID API.
+func (w *wlanState) multi() bool {
+	return w.security == nil
+}
+
+// wlanState retrieves the WLAN configuration, using the multi-SSID API when
+// supported by the firmware.
+func (c *Client) wlanState() (*wlanState, error) {
+	basic, err := c.WlanConfig()
+	if err != nil {
+		return nil, err
+	}
+	w := &wlanState{basic: basic}
+	channel := xmlInt(basic, "WifiChannel")
+
+	multi, err := c.Do("api/wlan/multi-basic-settings", nil)
+	switch {
+	case IsErrorCode(err, ErrorCodeNotSupported):
+		// single SSID
+		w.security, err = c.Do("api/wlan/security-settings", nil)
+		if err != nil {
+			return nil, err
+		}
+		band := WlanBand24GHz
+		if xmlString(basic, "wififrequency") == "1" {
+			band = WlanBand5GHz
+		}
+		w.settings = []WlanSettings{{
+			Enabled:    xmlString(basic, "WifiEnable") == "1",
+			SSID:       xmlString(basic, "WifiSsid"),
+			Hidden:     xmlString(basic, "WifiHide") == "1",
+			Channel:    channel,
+			Band:       band,
+			MaxClients: xmlInt(basic, "WifiMaxAssoc"),
+			AuthMode:   WlanAuthMode(xmlString(w.security, "WifiAuthmode")),
+			Encryption: xmlString(w.security, "WifiWpaencryptionmodes"),
+			Key:        xmlString(w.security, "WifiWpapsk"),
+		}}
+		return w, nil
+
+	case err != nil:
+		return nil, err
+	}
+
+	if l, ok := multi["Ssids"].(map[string]interface{}); ok {
+		w.ssids = xmlList(l["Ssid"])
+	}
+	for _, m := range w.ssids {
+		// the radio is part of the TR-069 style id
+		band := WlanBand24GHz
+		if strings.Contains(xmlString(m, "ID"), ".Radio.2.") {
+			band = WlanBand5GHz
+		}
+		w.settings = append(w.settings, WlanSettings{
+			Index:      uint(xmlInt(m, "Index")),
+			Enabled:    xmlString(m, "WifiEnable") == "1",
+			SSID:       xmlString(m, "WifiSsid"),
+			Hidden:     xmlString(m, "WifiBroadcast") == "1",
+			Channel:    channel,
+			Band:       band,
+			MaxClients: xmlInt(m, "WifiMaxassoc"),
+			AuthMode:   WlanAuthMode(xmlString(m, "WifiAuthmode")),
+			Encryption: xmlString(m, "WifiWpaencryptionmodes"),
+			Key:        xmlString(m, "WifiWpapsk"),
+		})
+	}
+
+	return w, nil
+}
+
+// WlanSettings retrieves the settings of the WiFi networks.
+func (c *Client) WlanSettings() ([]WlanSettings, error) {
+	w, err := c.wlanState()
+	if err != nil {
+		return nil, err
+	}
+	return w.settings, nil
+}
+
+// WlanSettingsSet changes the settings of the WiFi network with the specified
+// SSID index. The current settings are retrieved and passed to update, and the
+// result is written back to the device, preserving any additional settings
+// reported by the firmware. When required by the device, the security
+// settings (including the key) are sent encrypted. ErrWlanKeyMasked is
+// returned when the firmware reports masked keys, as they would be written
+// back as is. As Band is read-only, changing it returns ErrInvalidValue.
+//
+// The WiFi is restarted by the device, dropping connected clients.
+func (c *Client) WlanSettingsSet(index uint, update func(*WlanSettings)) (bool, error) {
+	w, err := c.wlanState()
+	if err != nil {
+		return false, err
+	}
+
+	i := -1
+	for j, s := range w.settings {
+		if s.Index == index {
+			i = j
+		}
+	}
+	if i == -1 {
+		return false, ErrSsidNotFound
+	}
+
+	s := w.settings[i]
+	update(&s)
+	if s.Band != w.settings[i].Band {
+		return false, ErrInvalidValue
+	}
+	if err = s.validate(); err != nil {
+		return false, err
+	}
+
+	if !w.multi() {
+		if IsMasked(s.Key) {
+			return false, ErrWlanKeyMasked
+		}
+
+		// security settings first, as they are the most likely to be
+		// rejected, leaving the basic settings untouched
+		ok, err := c.doReqCheckOKEncrypted("api/wlan/security-settings", SimpleRequestXML(append(xmlAppendExtra([]string{
+			"WifiAuthmode", string(s.AuthMode),
+			"WifiWpaencryptionmodes", xmlEscape(s.Encryption),
+			"WifiWpapsk", xmlEscape(s.Key),
+		}, w.security, "WifiRestart"), "WifiRestart", "1")...))
+		if err != nil || !ok {
+			return ok, err
+		}
+
+		// note: the order is important!
+		return c.doReqCheckOK("api/wlan/basic-settings", SimpleRequestXML(append(xmlAppendExtra([]string{
+			"WifiSsid", xmlEscape(s.SSID),
+			"WifiHide", boolToString(s.Hidden),
+			"WifiChannel", strconv.Itoa(s.Channel),
+			"WifiMaxAssoc", strconv.Itoa(s.MaxClients),
+			"WifiEnable", boolToString(s.Enabled),
+		}, w.basic, "WifiRestart"), "WifiRestart", "1")...))
+	}
+
+	// channel is a radio setting
+	if s.Channel != w.settings[i].Channel {
+		ok, err := c.doReqCheckOK("api/wlan/basic-settings", SimpleRequestXML(append(xmlAppendExtra([]string{
+			"WifiChannel", strconv.Itoa(s.Channel),
+		}, w.basic, "WifiRestart"), "WifiRestart", "1")...))
+		if err != nil || !ok {
+			return ok, err
+		}
+	}
+
+	return c.wlanMultiSet(w, i, s)
+}
+
+// wlanMultiSet writes all SSIDs of the multi-SSID configuration back to the
+// device, replacing the settings of SSID i with s. The extra name/value pairs
+// are added to SSID i, overriding the settings reported by the firmware.
+//
+// As the keys of all SSIDs are written back, ErrWlanKeyMasked is returned when
+// the firmware reported masked keys.
+func (c *Client) wlanMultiSet(w *wlanState, i int, s WlanSettings, extra ...string) (bool, error) {
+	var ssids string
+	for j, m := range w.ssids {
+		e, x := w.settings[j], []string(nil)
+		if j == i {
+			e, x = s, extra
+		}
+		if IsMasked(e.Key) {
+			return false, ErrWlanKeyMasked
+		}
+		ssids += "    <Ssid>\n" + xmlPairsString("      ", xmlAppendExtra(append([]string{
+			"Index", fmt.Sprintf("%d", e.Index),
+			"WifiEnable", boolToString(e.Enabled),
+			"WifiSsid", xmlEscape(e.SSID),
+			"WifiBroadcast", boolToString(e.Hidden),
+			"WifiMaxassoc", strconv.Itoa(e.MaxClients),
+			"WifiAuthmode", string(e.AuthMode),
+			"WifiWpaencryptionmodes", xmlEscape(e.Encryption),
+			"WifiWpapsk", xmlEscape(e.Key),
+		}, x...), m)...) + "    </Ssid>\n"
+	}
+
+	return c.doReqCheckOKEncrypted("api/wlan/multi-basic-settings", SimpleRequestXML(
+		"Ssids", "\n"+ssids+"  ",
+		"WifiRestart", "1",
+	))
+}
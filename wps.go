@@ -0,0 +1,171 @@
+package hilink
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrInvalidWpsPin is the invalid WPS PIN error.
+	ErrInvalidWpsPin = errors.New("invalid WPS PIN")
+
+	// ErrWpsTimeout is the error returned when no client joined during the WPS
+	// window.
+	ErrWpsTimeout = errors.New("wps window expired")
+)
+
+// WpsWindow is the time during which a client can join after WPS push-button
+// mode is triggered.
+const WpsWindow = 2 * time.Minute
+
+// WpsState is the WPS state of the primary WiFi network. APPin is empty when
+// the device does not support the AP PIN.
+type WpsState struct {
+	Enabled bool
+	APPin   string
+}
+
+// WpsInfo retrieves WPS information.
+func (c *Client) WpsInfo() (XMLData, error) {
+	return c.Do("api/wlan/wps", nil)
+}
+
+// WpsState retrieves the WPS state of the primary WiFi network.
+func (c *Client) WpsState() (*WpsState, error) {
+	d, err := c.WpsInfo()
+	if err != nil {
+		return nil, err
+	}
+	s := &WpsState{
+		Enabled: xmlString(d, "WifiWpsenbl") == "1",
+	}
+
+	s.APPin, err = c.WpsAPPin()
+	if err != nil && !IsErrorCode(err, ErrorCodeNotSupported) {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// WpsPushButton triggers WPS push-button mode, allowing a client to join
+// during the WPS window.
+func (c *Client) WpsPushButton() (bool, error) {
+	return c.doReqCheckOK("api/wlan/wps-pbc", SimpleRequestXML(
+		"WPSMode", "1",
+	))
+}
+
+// wlanHosts retrieves the MAC addresses of the WiFi hosts.
+func (c *Client) wlanHosts() (map[string]bool, error) {
+	hosts, err := c.HostList()
+	if err != nil {
+		return nil, err
+	}
+	macs := make(map[string]bool)
+	for _, h := range hosts {
+		if h.Interface == HostInterfaceWLAN {
+			macs[h.MACAddress] = true
+		}
+	}
+	return macs, nil
+}
+
+// WpsPushButtonAndWait triggers WPS push-button mode and waits until a new
+// client joins the WiFi, returning the client. ErrWpsTimeout is returned when
+// no client joined during the WPS window.
+func (c *Client) WpsPushButtonAndWait(ctx context.Context) (*Host, error) {
+	before, err := c.wlanHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = CheckOK(c.WpsPushButton()); err != nil {
+		return nil, err
+	}
+
+	window, cancel := context.WithTimeout(ctx, WpsWindow)
+	defer cancel()
+
+	var joined *Host
+	err = c.poll(window, func() (bool, error) {
+		hosts, err := c.HostList()
+		if err != nil {
+			return false, err
+		}
+		for _, h := range hosts {
+			if h.Interface == HostInterfaceWLAN && !before[h.MACAddress] {
+				joined = &h
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	switch {
+	case err != nil && ctx.Err() == nil:
+		return nil, ErrWpsTimeout
+	case err != nil:
+		return nil, err
+	}
+
+	return joined, nil
+}
+
+// wpsChecksum computes the checksum digit of the first 7 digits of a WPS PIN.
+func wpsChecksum(pin int) int {
+	accum := 0
+	for pin > 0 {
+		accum += 3 * (pin % 10)
+		pin /= 10
+		accum += pin % 10
+		pin /= 10
+	}
+	return (10 - accum%10) % 10
+}
+
+// ValidWpsPin determines if pin is a valid 8 digit WPS PIN.
+func ValidWpsPin(pin string) bool {
+	if len(pin) != 8 {
+		return false
+	}
+	i, err := strconv.Atoi(pin)
+	if err != nil || i < 0 {
+		return false
+	}
+	return wpsChecksum(i/10) == i%10
+}
+
+// WpsAPPin retrieves the WPS PIN of the device (the AP PIN).
+func (c *Client) WpsAPPin() (string, error) {
+	return c.doReqString("api/wlan/wps-appin", nil, "wpsappin")
+}
+
+// WpsAPPinSet sets the WPS PIN of the device (the AP PIN).
+func (c *Client) WpsAPPinSet(pin string) (bool, error) {
+	if !ValidWpsPin(pin) {
+		return false, ErrInvalidWpsPin
+	}
+	return c.doReqCheckOK("api/wlan/wps-appin", SimpleRequestXML(
+		"wpsappin", pin,
+	))
+}
+
+// WpsAPPinGenerate sets a new random WPS PIN on the device, returning the PIN.
+func (c *Client) WpsAPPinGenerate() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000000))
+	if err != nil {
+		return "", err
+	}
+	i := int(n.Int64())
+	pin := fmt.Sprintf("%07d%d", i, wpsChecksum(i))
+
+	if err = CheckOK(c.WpsAPPinSet(pin)); err != nil {
+		return "", err
+	}
+	return pin, nil
+}